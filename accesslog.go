@@ -0,0 +1,80 @@
+package mux
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statusWriter records the status of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// AccessLog returns middleware logging every request with logger at
+// slog.LevelInfo once served, with its method, the route matched by an
+// HTTPHandler below it rather than the raw path, so log and metrics
+// cardinality stays bounded, its status and its duration. Requests nothing
+// matched are logged without a route.
+//
+// The tags of the route are logged too: a "key=value" tag such as
+// "team=payments" or "slo=gold" as a field of its own, others together
+// under "tags".
+func AccessLog(logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r = r.WithContext(WithRoute(r.Context()))
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			attrs := []slog.Attr{slog.String("method", r.Method)}
+			if pattern, ok := RouteFromContext(r.Context()); ok {
+				attrs = append(attrs, slog.String("route", pattern))
+				attrs = append(attrs, tagAttrs(RouteTagsFromContext(r.Context()))...)
+			}
+			attrs = append(attrs, slog.Int("status", sw.status), slog.Duration("duration", time.Since(start)))
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "mux: request", attrs...)
+		})
+	}
+}
+
+func tagAttrs(tags []string) []slog.Attr {
+	var attrs []slog.Attr
+	var rest []string
+	for _, t := range tags {
+		if k, v, ok := strings.Cut(t, "="); ok && k != "" {
+			attrs = append(attrs, slog.String(k, v))
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	if rest != nil {
+		attrs = append(attrs, slog.Any("tags", rest))
+	}
+	return attrs
+}
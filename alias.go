@@ -0,0 +1,81 @@
+package mux
+
+// Alias registers alias as another pattern for the entry registered under
+// canonical, reporting whether there is one. The alias shares the entry's
+// value, metadata, priority and other settings, so mapping either pattern
+// again updates both, e.g. to keep deprecated route spellings working
+// without duplicating their configuration. Deleting the canonical pattern
+// deletes its aliases. An alias replaces any entry registered under it, and
+// both patterns are returned by MatchAll when both match. Entries with
+// their own trimmer cannot be aliased.
+func (m *Mux) Alias(alias, canonical string) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	ckey, e, ok := m.lookup(canonical)
+	if !ok || e.trim != nil {
+		return false
+	}
+	if c, ok := m.aliases[ckey]; ok {
+		ckey = c
+	}
+	for _, p := range m.expand(alias) {
+		m.alias(m.trimPattern(p), ckey, e, p)
+	}
+	return true
+}
+
+// alias stores the entry e under key, trimmed from orig, as an alias of
+// ckey.
+func (m *Mux) alias(key, ckey string, e *entry, orig string) {
+	if key == ckey {
+		return
+	}
+	if old, ok := m.m[key]; ok && old != e {
+		m.remove(key, old)
+	}
+	if m.aliases == nil {
+		m.aliases = make(map[string]string)
+	}
+	if m.intern {
+		key = Intern(key)
+	}
+	m.m[key] = e
+	m.aliases[key] = ckey
+	delete(m.aliasOriginals, key)
+	if orig != key {
+		if m.aliasOriginals == nil {
+			m.aliasOriginals = make(map[string]string)
+		}
+		m.aliasOriginals[key] = orig
+	}
+	m.order = nil
+}
+
+// removeAliases deletes key from the aliases, along with the aliases of key
+// when it is canonical.
+func (m *Mux) removeAliases(key string) {
+	if _, ok := m.aliases[key]; ok {
+		delete(m.aliases, key)
+		delete(m.aliasOriginals, key)
+		return
+	}
+	for a, c := range m.aliases {
+		if c == key {
+			delete(m.m, a)
+			delete(m.aliases, a)
+			delete(m.aliasOriginals, a)
+		}
+	}
+}
+
+// filterOf returns the prefilter test of the entry e stored under key.
+// The test is derived from the canonical pattern, so aliases go without.
+func (m *Mux) filterOf(key string, e *entry) func(string) bool {
+	if len(m.aliases) > 0 {
+		if _, ok := m.aliases[key]; ok {
+			return nil
+		}
+	}
+	return e.filter
+}
@@ -0,0 +1,64 @@
+package mux
+
+import (
+	"path"
+	"strings"
+)
+
+// Fingerprint splits the content hash out of a fingerprinted asset path,
+// returning "static/main.js" and "3f2a9c1b" for "static/main.3f2a9c1b.js"
+// or "static/main-3f2a9c1b.js". A hash is the last segment of the base name
+// before its extension, set off by "." or "-", of at least 8 letters,
+// digits and underscores including a digit. Paths without one are
+// returned as they are.
+func Fingerprint(s string) (stripped, hash string) {
+	dir, base := path.Split(s)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	i := strings.LastIndexAny(stem, ".-")
+	if i <= 0 || !isHash(stem[i+1:]) {
+		return s, ""
+	}
+	return dir + stem[:i] + ext, stem[i+1:]
+}
+
+func isHash(s string) bool {
+	if len(s) < 8 {
+		return false
+	}
+	digit := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digit = true
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		default:
+			return false
+		}
+	}
+	return digit
+}
+
+// FingerprintTrim strips the content hash from fingerprinted asset paths,
+// see Fingerprint, so "main.3f2a9c1b.js" is mapped and matched as "main.js".
+var FingerprintTrim = func(s string) string {
+	s, _ = Fingerprint(s)
+	return s
+}
+
+// NewAssetMux returns a file Mux, see NewFileMux, ignoring the content
+// hashes of fingerprinted asset paths in both patterns and inputs.
+func NewAssetMux() *Mux {
+	return New(Config{
+		TrimPattern: FingerprintTrim,
+		TrimString:  CombineTrimFn(FingerprintTrim, FileTrim),
+		Matcher:     FileMatch,
+	})
+}
+
+// MatchAsset is Match also returning the content hash of s, if any, e.g.
+// to check it against the current build or set caching headers.
+func (m *Mux) MatchAsset(s string, opts ...MatchOption) (val interface{}, hash string) {
+	_, hash = Fingerprint(FileTrim(s))
+	return m.Match(s, opts...), hash
+}
@@ -0,0 +1,112 @@
+package mux
+
+import (
+	"errors"
+	"fmt"
+)
+
+// table is the state Promote switches between Muxes.
+type table struct {
+	m           map[string]*entry
+	aliases     map[string]string
+	originals   map[string]string
+	index       int
+	ownTrims    int
+	ownMatchers int
+}
+
+// swapTable installs t, returning the table it replaces. The caller must
+// hold the write lock.
+func (m *Mux) swapTable(t table) table {
+	old := table{m.m, m.aliases, m.aliasOriginals, m.index, m.ownTrims, m.ownMatchers}
+	m.m, m.aliases, m.aliasOriginals, m.index, m.ownTrims, m.ownMatchers = t.m, t.aliases, t.originals, t.index, t.ownTrims, t.ownMatchers
+	m.order = nil
+	return old
+}
+
+// Stage returns an empty Mux configured like m, without its hook and
+// recent matches, to fill with a replacement table for Promote. Settings
+// changed on the staged Mux are not promoted.
+func (m *Mux) Stage() *Mux {
+	c := m.Config()
+	c.Hook = nil
+	c.RecentMatches = 0
+	return New(c)
+}
+
+// Verify matches every sample input, reporting those not won by the
+// pattern they map to, "" for no match, and, unless there is a TieBreak,
+// those with several entries tied on the best match as *MatchError values
+// wrapping ErrAmbiguous. The errors are joined with errors.Join.
+func (m *Mux) Verify(samples map[string]string) error {
+	m.mtx.RLock()
+	tieBreak := m.tieBreak != nil
+	m.mtx.RUnlock()
+
+	var errs []error
+	var buf []MatchResult
+	for s, want := range samples {
+		r, err := m.MatchResult(s)
+		if errors.Is(err, ErrAmbiguous) {
+			errs = append(errs, err)
+			continue
+		}
+		got := ""
+		if err == nil {
+			got = r.Pattern
+		}
+		if err == nil && !tieBreak {
+			// MatchAll only serves to find entries tied with the winner
+			buf = m.MatchAllInto(s, buf, Offset(0))
+			tie := &MatchError{Input: s, Err: ErrAmbiguous, Patterns: []string{r.Pattern}}
+			for _, c := range buf {
+				if c.Pattern != r.Pattern && c.Priority == r.Priority && m.compareScores(c.Score, r.Score) == 0 {
+					tie.Patterns = append(tie.Patterns, c.Pattern)
+				}
+			}
+			if len(tie.Patterns) > 1 {
+				errs = append(errs, tie)
+				continue
+			}
+		}
+		if got != want {
+			errs = append(errs, fmt.Errorf("mux: %q matches %q, want %q", s, got, want))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Promote verifies next, normally made by Stage, against samples and, if
+// it passes, makes its entries the table of m in one atomic switch,
+// leaving next empty. The table switched out is kept for Revert. Entries
+// switched out are not reported to OnEvict.
+func (m *Mux) Promote(next *Mux, samples map[string]string) error {
+	if err := next.Verify(samples); err != nil {
+		return err
+	}
+
+	next.mtx.Lock()
+	t := next.swapTable(table{m: make(map[string]*entry)})
+	next.mtx.Unlock()
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	old := m.swapTable(t)
+	m.standby = &old
+	return nil
+}
+
+// Revert switches back to the table replaced by the last Promote or
+// Revert, reporting whether there is one.
+func (m *Mux) Revert() bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.standby == nil {
+		return false
+	}
+	old := m.swapTable(*m.standby)
+	m.standby = &old
+	return true
+}
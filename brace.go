@@ -0,0 +1,47 @@
+package mux
+
+// ExpandBraces performs shell-style brace expansion, so "/api/{v1,v2}/users"
+// yields "/api/v1/users" and "/api/v2/users". Groups may nest and combine;
+// a group without a top-level comma, such as a regexp quantifier "{2}", and
+// unbalanced braces are kept literally.
+func ExpandBraces(s string) []string {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '{' {
+			continue
+		}
+
+		depth, commas := 0, []int(nil)
+		for j := i; j < len(s); j++ {
+			switch s[j] {
+			case '{':
+				depth++
+			case ',':
+				if depth == 1 {
+					commas = append(commas, j)
+				}
+			case '}':
+				depth--
+			}
+			if depth > 0 {
+				continue
+			}
+			if commas == nil {
+				break
+			}
+
+			prefix, suffixes := s[:i], ExpandBraces(s[j+1:])
+			var out []string
+			start := i + 1
+			for _, end := range append(commas, j) {
+				for _, alt := range ExpandBraces(s[start:end]) {
+					for _, suffix := range suffixes {
+						out = append(out, prefix+alt+suffix)
+					}
+				}
+				start = end + 1
+			}
+			return out
+		}
+	}
+	return []string{s}
+}
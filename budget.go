@@ -0,0 +1,87 @@
+package mux
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// MapWithBudget maps pattern to val, skipping it for inputs it takes longer
+// than budget to match, as with Config.MatchBudget. A budget of 0 restores
+// the Mux budget.
+func (m *Mux) MapWithBudget(pattern string, val interface{}, budget time.Duration) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, p := range m.expand(pattern) {
+		if e := m.mapEntry(p, val); e != nil {
+			e.budget = budget
+		}
+	}
+}
+
+func (m *Mux) budgetOf(e *entry) time.Duration {
+	if e.budget > 0 {
+		return e.budget
+	}
+	return m.budget
+}
+
+// matchWithin is match giving up after d. The entry is read before the
+// match starts, so the goroutine left running past d does not race with
+// later changes to it. Whichever of the goroutine finishing and the caller
+// giving up comes first claims the result, so a panic is either raised in
+// the caller or reported by the goroutine.
+func (m *Mux) matchWithin(ctx context.Context, d time.Duration, p string, e *entry, raw, s string) (ok bool, score int) {
+	type result struct {
+		ok    bool
+		score int
+		panic *PanicError
+	}
+	c := make(chan result, 1)
+	var claimed atomic.Bool
+	index, trim, filter, f := e.index, e.trim, m.filterOf(p, e), m.matcherOf(ctx, e)
+	go func() {
+		var r result
+		defer func() {
+			if v := recover(); v != nil {
+				r = result{panic: &PanicError{Pattern: p, Input: raw, Value: v, Stack: debug.Stack()}}
+			}
+			if claimed.CompareAndSwap(false, true) {
+				c <- r
+			} else if r.panic != nil {
+				m.reportPanic(r.panic)
+			}
+		}()
+		r.ok, r.score = matchEntry(p, index, trim, filter, f, raw, s)
+	}()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	var r result
+	select {
+	case r = <-c:
+	case <-t.C:
+		if !claimed.CompareAndSwap(false, true) {
+			r = <-c
+			break
+		}
+		switch {
+		case m.onBudget != nil:
+			m.onBudget(p, raw, d)
+		case m.logger != nil:
+			m.logger.Warn("mux: match over budget", slog.String("pattern", p), slog.String("input", raw), slog.Duration("budget", d))
+		}
+		return false, 0
+	}
+	if r.panic != nil {
+		if !m.recoverPanics {
+			panic(r.panic.Value)
+		}
+		m.reportPanic(r.panic)
+		return false, 0
+	}
+	return r.ok, r.score
+}
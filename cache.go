@@ -0,0 +1,45 @@
+package mux
+
+import "sync"
+
+// patternCacheSize bounds the values a patternCache holds, so matchers
+// fed patterns that come and go do not grow without limit.
+const patternCacheSize = 4096
+
+// patternCache caches values derived from patterns, such as compiled
+// rules. Once full it stops caching, rather than evicting on every miss,
+// which would only churn for tables of more patterns than it holds, and
+// starts over after as many misses so patterns no longer matched against
+// give way.
+type patternCache[V any] struct {
+	mtx    sync.RWMutex
+	m      map[string]V
+	misses int // since the cache filled up
+}
+
+// get returns the value of pattern, making it with f when it is not
+// cached.
+func (c *patternCache[V]) get(pattern string, f func(pattern string) V) V {
+	c.mtx.RLock()
+	v, ok := c.m[pattern]
+	c.mtx.RUnlock()
+	if ok {
+		return v
+	}
+
+	v = f(pattern)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	switch {
+	case c.m == nil:
+		c.m = make(map[string]V)
+	case len(c.m) >= patternCacheSize:
+		if c.misses++; c.misses < patternCacheSize {
+			return v
+		}
+		clear(c.m)
+		c.misses = 0
+	}
+	c.m[pattern] = v
+	return v
+}
@@ -0,0 +1,51 @@
+package mux
+
+import "context"
+
+// CascadeMux matches inputs against a list of Muxes, e.g. layered system,
+// plugin and user routes, reporting which one supplied the winner.
+type CascadeMux struct {
+	muxes []*Mux
+
+	// MergeScores makes every Mux match and the best result win, ranked by
+	// priority, then by score as compared by the first Mux, then by order
+	// in the cascade. When false, the first Mux with a match wins.
+	MergeScores bool
+}
+
+// Cascade returns a CascadeMux over muxes, in order.
+func Cascade(muxes ...*Mux) *CascadeMux {
+	return &CascadeMux{muxes: muxes}
+}
+
+// Match returns the value of the best match for s and the position in the
+// cascade of the Mux it came from, or -1 when nothing matches.
+func (c *CascadeMux) Match(s string, opts ...MatchOption) (val interface{}, layer int) {
+	r, layer, _ := c.MatchResult(s, opts...)
+	return r.Value, layer
+}
+
+// MatchResult is like Match, returning the whole result.
+func (c *CascadeMux) MatchResult(s string, opts ...MatchOption) (r MatchResult, layer int, ok bool) {
+	layer = -1
+	for i, m := range c.muxes {
+		mr, found, _ := m.matchBest(context.Background(), s, opts)
+		if !found {
+			continue
+		}
+		if !c.MergeScores {
+			return mr, i, true
+		}
+		if !ok || c.ranksAbove(mr, r) {
+			r, layer, ok = mr, i, true
+		}
+	}
+	return
+}
+
+func (c *CascadeMux) ranksAbove(a, b MatchResult) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return c.muxes[0].compareScores(a.Score, b.Score) > 0
+}
@@ -0,0 +1,70 @@
+package mux
+
+import "strings"
+
+// PluralFunc returns the plural category, such as "one" or "other", of the
+// count n in the language lang.
+type PluralFunc func(lang string, n int) string
+
+// EnglishPlural is the PluralFunc of English and many other languages:
+// "one" for 1 and "other" otherwise.
+var EnglishPlural = func(lang string, n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+// Catalog resolves message keys in a language to translations, falling
+// back both along the key, like DottedKeyMatch, and along the language,
+// like LangMatch. A message in a fallback language ("de" for "de-AT") beats
+// one for an ancestor key, and messages added for the language "*" serve
+// every language.
+type Catalog struct {
+	m      *Mux
+	plural PluralFunc
+}
+
+// NewCatalog returns an empty Catalog using plural to pick plural forms,
+// EnglishPlural when nil.
+func NewCatalog(plural PluralFunc) *Catalog {
+	if plural == nil {
+		plural = EnglishPlural
+	}
+	return &Catalog{
+		m: NewCompositeMux([]Dimension{
+			{Matcher: DottedKeyMatch},
+			{TrimPattern: LangTrim, TrimString: LangTrim, Matcher: LangMatch},
+		}, nil),
+		plural: plural,
+	}
+}
+
+// Add adds the translation msg of key in lang. Plural forms are added as
+// keys under the message key named after their category, e.g.
+// "cart.items.one" and "cart.items.other".
+func (c *Catalog) Add(lang, key, msg string) {
+	c.m.Map(JoinKey(key, lang), msg)
+}
+
+// Lookup returns the translation of key in lang.
+func (c *Catalog) Lookup(lang, key string) (string, bool) {
+	return c.m.MatchString(JoinKey(key, lang))
+}
+
+// Plural returns the translation of key in lang for the count n: the form
+// for the plural category of n, then the "other" form, then the message
+// of key itself, each with the usual fallbacks.
+func (c *Catalog) Plural(lang, key string, n int) (string, bool) {
+	for _, form := range []string{c.plural(LangTrim(lang), n), "other"} {
+		fkey := key + "." + form
+		r, err := c.m.MatchResult(JoinKey(fkey, lang))
+		// only a match on the form itself counts, not one on its ancestors
+		if err == nil && strings.Count(SplitKey(r.Pattern)[0], ".") == strings.Count(fkey, ".") {
+			if msg, ok := r.Value.(string); ok {
+				return msg, true
+			}
+		}
+	}
+	return c.Lookup(lang, key)
+}
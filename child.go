@@ -0,0 +1,59 @@
+package mux
+
+// Config returns the configuration the Mux currently runs with.
+func (m *Mux) Config() Config {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return Config{
+		TrimPattern:      m.trimPattern,
+		TrimString:       m.trimString,
+		TrimStringErr:    m.trimStringErr,
+		Matcher:          m.matcher,
+		ContextMatcher:   m.ctxMatcher,
+		ExpandBraces:     m.braces,
+		Vars:             m.vars,
+		CompareScores:    m.compare,
+		ScoreBound:       m.scoreBound,
+		Prefilter:        m.prefilter,
+		InternPatterns:   m.intern,
+		Reconfig:         m.reconfig,
+		Codec:            m.codec,
+		Hook:             m.hook,
+		Logger:           m.logger,
+		RecoverPanics:    m.recoverPanics,
+		OnPanic:          m.onPanic,
+		MatchBudget:      m.budget,
+		OnBudgetExceeded: m.onBudget,
+		MinScore:         m.minScore,
+		MaxResults:       m.maxResults,
+		Fallback:         m.fallback,
+		OnEvict:          m.onEvict,
+		StrictTies:       m.strict,
+		TrackStats:       m.stats,
+		MaxEntries:       m.maxEntries,
+		Eviction:         m.eviction,
+		OrderedResults:   m.sortAll,
+		RecentMatches:    m.recent.size(),
+		Name:             m.name,
+		ProfileLabels:    m.profile,
+		TieBreak:         m.tieBreak,
+		History:          m.history,
+	}
+}
+
+// NewChild returns an empty Mux configured like parent, layered on top of
+// it, e.g. for per-tenant overrides of a shared table. Match falls through
+// to parent when the child has no match, and MatchAll adds the matches of
+// parent entries whose pattern has no matching entry in the child. Lookup,
+// Entries and the other table queries only see the child's own entries.
+func NewChild(parent *Mux) *Mux {
+	m := New(parent.Config())
+	m.parent = parent
+	return m
+}
+
+// Parent returns the Mux m was layered on by NewChild, or nil.
+func (m *Mux) Parent() *Mux {
+	return m.parent
+}
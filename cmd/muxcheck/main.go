@@ -0,0 +1,167 @@
+// Command muxcheck loads a route file and shows how sample inputs are
+// matched against it.
+//
+// Usage:
+//
+//	muxcheck -routes routes.json [-type path [-match spec] [-trim spec]] [input ...]
+//	muxcheck -routes routes.json -validate
+//
+// Without -type the Mux is configured by the "mux" section of the route
+// file, see mux.LoadMux. The -match and -trim flags replace the matcher and
+// trimmers of the mux type with spec strings such as "longest(prefix)" and
+// "path|lowercase", see mux.Spec.
+//
+// With -validate muxcheck only reports the problems mux.ValidateFile finds
+// in the route file, one per line, for use in CI.
+//
+// Inputs are read one per line from standard input when none are given.
+// For every input muxcheck prints the winning pattern, its score and
+// value, followed by every matching candidate, best first.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/huangml/mux"
+)
+
+var types = map[string]func() *mux.Mux{
+	"strict":    mux.NewStrictMux,
+	"path":      mux.NewPathMux,
+	"range":     mux.NewRangeMux,
+	"schedule":  mux.NewScheduleMux,
+	"mime":      mux.NewMIMEMux,
+	"file":      mux.NewFileMux,
+	"lang":      mux.NewLangMux,
+	"label":     mux.NewLabelMux,
+	"gitignore": mux.NewGitignoreMux,
+	"dotted":    mux.NewDottedKeyMux,
+}
+
+func typeNames() string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func check(w io.Writer, m *mux.Mux, input string) {
+	fmt.Fprintf(w, "%s\n", input)
+
+	// paging, even from offset 0, ranks candidates best first
+	results := m.MatchAllInto(input, nil, mux.Offset(0))
+	if len(results) == 0 {
+		fmt.Fprintf(w, "  no match\n\n")
+		return
+	}
+
+	val, pattern, score := m.MatchWithPatternScore(input)
+	fmt.Fprintf(w, "  winner\t%s\tscore %d\t%v\n", pattern, score, val)
+	for _, r := range results {
+		fmt.Fprintf(w, "  candidate\t%s\tscore %d\tpriority %d\n", r.Pattern, r.Score, r.Priority)
+	}
+	fmt.Fprintln(w)
+}
+
+// load builds the Mux described by the flags, or the route file itself
+// when no type is given.
+func load(routes, typ, match, trim string) (*mux.Mux, error) {
+	if typ == "" {
+		if match != "" || trim != "" {
+			return nil, fmt.Errorf("-match and -trim need -type")
+		}
+		return mux.LoadMuxFile(routes)
+	}
+
+	newMux, ok := types[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", typ)
+	}
+	m := newMux()
+	if match != "" {
+		f, err := mux.ParseMatcher(match)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.SetMatcher(f); err != nil {
+			return nil, err
+		}
+	}
+	if trim != "" {
+		f, err := mux.ParseTrimmer(trim)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.SetPatternTrimmer(f); err != nil {
+			return nil, err
+		}
+		if err := m.SetStringTrimmer(f); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.LoadRoutesFile(routes); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run runs muxcheck, returning its exit code once all output is flushed.
+func run() int {
+	routes := flag.String("routes", "", "route file to load (required)")
+	typ := flag.String("type", "", "mux type: "+typeNames()+" (default: as configured by the route file)")
+	match := flag.String("match", "", "matcher spec using: "+strings.Join(mux.Matchers(), ", "))
+	trim := flag.String("trim", "", "trimmer spec using: "+strings.Join(mux.Trimmers(), ", "))
+	validate := flag.Bool("validate", false, "only validate the route file")
+	flag.Parse()
+
+	if *routes == "" {
+		flag.Usage()
+		return 2
+	}
+	if *validate {
+		if err := mux.ValidateFile(*routes); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	m, err := load(*routes, *typ, *match, *trim)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "muxcheck:", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	if flag.NArg() > 0 {
+		for _, input := range flag.Args() {
+			check(w, m, input)
+		}
+		return 0
+	}
+
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		check(w, m, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, "muxcheck:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package mux
+
+import "strings"
+
+// KeySeparator separates the parts of a composite key built by JoinKey.
+const KeySeparator = "\x1f"
+
+// JoinKey builds a composite key, usable both as a pattern and as an input,
+// from one part per dimension.
+func JoinKey(parts ...string) string {
+	return strings.Join(parts, KeySeparator)
+}
+
+// SplitKey splits a composite key built by JoinKey into its parts.
+func SplitKey(key string) []string {
+	return strings.Split(key, KeySeparator)
+}
+
+// Dimension configures how one part of a composite key is trimmed and
+// matched. Nil fields default as they do in Config.
+type Dimension struct {
+	TrimPattern TrimFunc
+	TrimString  TrimFunc
+	Matcher     MatchFunc
+}
+
+// CombineFunc merges per-dimension scores into the score of the whole key.
+type CombineFunc func(scores []int) int
+
+var SumScores = func(scores []int) (sum int) {
+	for _, s := range scores {
+		sum += s
+	}
+	return
+}
+
+// WeightedScoresFn returns a CombineFunc summing scores multiplied by the
+// weight of their dimension. Dimensions without a weight count once.
+func WeightedScoresFn(weights ...int) CombineFunc {
+	return func(scores []int) (sum int) {
+		for i, s := range scores {
+			if i < len(weights) {
+				s *= weights[i]
+			}
+			sum += s
+		}
+		return
+	}
+}
+
+func compositeTrimFn(dims []Dimension, pick func(Dimension) TrimFunc) TrimFunc {
+	return func(s string) string {
+		parts := SplitKey(s)
+		if len(parts) != len(dims) {
+			return s
+		}
+		for i, d := range dims {
+			if f := pick(d); f != nil {
+				parts[i] = f(parts[i])
+			}
+		}
+		return JoinKey(parts...)
+	}
+}
+
+// CompositeTrimFns returns pattern and string trimmers applying each
+// dimension's trimmers to its part of a composite key.
+func CompositeTrimFns(dims []Dimension) (trimPattern, trimString TrimFunc) {
+	return compositeTrimFn(dims, func(d Dimension) TrimFunc { return d.TrimPattern }),
+		compositeTrimFn(dims, func(d Dimension) TrimFunc { return d.TrimString })
+}
+
+// CompositeMatchFn returns a matcher for composite keys that matches when
+// every dimension's matcher matches its part, scored by combine (SumScores
+// when nil). Keys with the wrong number of parts never match.
+func CompositeMatchFn(dims []Dimension, combine CombineFunc) MatchFunc {
+	if combine == nil {
+		combine = SumScores
+	}
+	return func(pattern, s string, index int) (ok bool, score int) {
+		ps, ss := SplitKey(pattern), SplitKey(s)
+		if len(ps) != len(dims) || len(ss) != len(dims) {
+			return false, 0
+		}
+
+		scores := make([]int, len(dims))
+		for i, d := range dims {
+			matcher := d.Matcher
+			if matcher == nil {
+				matcher = StrictMatch
+			}
+			if ok, scores[i] = matcher(ps[i], ss[i], index); !ok {
+				return false, 0
+			}
+		}
+		return true, combine(scores)
+	}
+}
+
+// NewCompositeMux returns a Mux whose patterns and inputs are composite keys
+// built with JoinKey, e.g. JoinKey("GET", "example.com", "/api/").
+func NewCompositeMux(dims []Dimension, combine CombineFunc) *Mux {
+	trimPattern, trimString := CompositeTrimFns(dims)
+	return New(Config{
+		TrimPattern: trimPattern,
+		TrimString:  trimString,
+		Matcher:     CompositeMatchFn(dims, combine),
+	})
+}
@@ -0,0 +1,38 @@
+package mux
+
+import "context"
+
+// MatchContext is like Match but gives up once ctx is done, returning
+// ctx.Err(). The context is checked between entries, so a single slow
+// matcher call is not interrupted.
+func (m *Mux) MatchContext(ctx context.Context, s string, opts ...MatchOption) (val interface{}, err error) {
+	r, _, err := m.matchBest(ctx, s, opts)
+	return r.Value, err
+}
+
+func (m *Mux) MatchWithPatternContext(ctx context.Context, s string, opts ...MatchOption) (val interface{}, pattern string, err error) {
+	r, _, err := m.matchBest(ctx, s, opts)
+	return r.Value, r.Pattern, err
+}
+
+func (m *Mux) MatchWithPatternScoreContext(ctx context.Context, s string, opts ...MatchOption) (val interface{}, pattern string, maxScore int, err error) {
+	r, _, err := m.matchBest(ctx, s, opts)
+	return r.Value, r.Pattern, r.Score, err
+}
+
+// MatchResultContext is like MatchResult but gives up once ctx is done,
+// returning ctx.Err().
+func (m *Mux) MatchResultContext(ctx context.Context, s string, opts ...MatchOption) (MatchResult, error) {
+	r, found, err := m.matchBest(ctx, s, opts)
+	if err == nil && !found {
+		err = &MatchError{Input: s, Err: ErrNoMatch}
+	}
+	return r, err
+}
+
+// MatchAllContext is like MatchAllInto with a nil buffer but gives up once
+// ctx is done, returning ctx.Err(). Results capped by Config.MaxResults are
+// returned with ErrTruncated.
+func (m *Mux) MatchAllContext(ctx context.Context, s string, opts ...MatchOption) ([]MatchResult, error) {
+	return m.matchAll(ctx, s, nil, opts)
+}
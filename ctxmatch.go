@@ -0,0 +1,26 @@
+package mux
+
+import "context"
+
+// ContextMatchFunc is a MatchFunc also given the context of the match, see
+// Config.ContextMatcher, e.g. to read request attributes or a deadline
+// stored in it.
+type ContextMatchFunc func(ctx context.Context, pattern, s string, index int) (ok bool, score int)
+
+// SetContextMatcher replaces the context matcher, a nil f restoring the
+// Mux matcher. As with SetMatcher, any score bound and prefilter are
+// removed.
+func (m *Mux) SetContextMatcher(f ContextMatchFunc) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := m.reconfigure(); err != nil {
+		return err
+	}
+	m.ctxMatcher = f
+	m.scoreBound = nil
+	m.order = nil
+	m.setPrefilter(nil)
+	m.updateExact()
+	return nil
+}
@@ -0,0 +1,117 @@
+package mux
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota + 1
+	Removed
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change describes how the entry under Pattern differs between two tables.
+// Old is nil for added entries and New is nil for removed ones.
+type Change struct {
+	Kind    ChangeKind
+	Pattern string
+	Old     *Entry
+	New     *Entry
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s => %v", c.Pattern, c.New.Value)
+	case Removed:
+		return fmt.Sprintf("- %s => %v", c.Pattern, c.Old.Value)
+	}
+	return fmt.Sprintf("~ %s => %v (was %v)", c.Pattern, c.New.Value, c.Old.Value)
+}
+
+// entryModified reports whether the registration of an entry changed,
+// ignoring its index and any per-entry matcher or trimmer, which cannot be
+// compared.
+func entryModified(a, b *Entry) bool {
+	return !sameSettings(a, b) || !reflect.DeepEqual(a.Value, b.Value)
+}
+
+// sameSettings reports whether a and b differ in nothing but their values.
+func sameSettings(a, b *Entry) bool {
+	return a.Weight == b.Weight && a.Priority == b.Priority && a.Budget == b.Budget &&
+		a.Rolled == b.Rolled && a.Rollout == b.Rollout && a.Disabled == b.Disabled && a.Adjust == b.Adjust &&
+		a.AliasOf == b.AliasOf &&
+		sameWindow(a.Window, b.Window) &&
+		reflect.DeepEqual(a.Metadata, b.Metadata) &&
+		reflect.DeepEqual(a.Tags, b.Tags)
+}
+
+// Diff reports the entries added, removed and modified when going from a
+// to b, ordered by pattern. Values and metadata are compared with
+// reflect.DeepEqual, so non-nil func values always count as modified.
+func Diff(a, b *Mux) []Change {
+	old := make(map[string]*Entry)
+	for _, e := range a.Entries() {
+		e := e
+		old[e.Pattern] = &e
+	}
+
+	var changes []Change
+	for _, e := range b.Entries() {
+		e := e
+		o, ok := old[e.Pattern]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: Added, Pattern: e.Pattern, New: &e})
+		case entryModified(o, &e):
+			changes = append(changes, Change{Kind: Modified, Pattern: e.Pattern, Old: o, New: &e})
+		}
+		delete(old, e.Pattern)
+	}
+	for p, o := range old {
+		changes = append(changes, Change{Kind: Removed, Pattern: p, Old: o})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Pattern < changes[j].Pattern })
+	return changes
+}
+
+// Equal reports whether a and b hold the same patterns, ranked in the same
+// insertion order, with the same values and registrations as compared by
+// Diff. Values are compared with valueEq, reflect.DeepEqual when nil.
+// Indexes themselves, configurations and statistics are not compared.
+func Equal(a, b *Mux, valueEq func(x, y interface{}) bool) bool {
+	if valueEq == nil {
+		valueEq = reflect.DeepEqual
+	}
+	ea, eb := a.Entries(), b.Entries()
+	if len(ea) != len(eb) {
+		return false
+	}
+	for i := range ea {
+		x, y := &ea[i], &eb[i]
+		if x.Pattern != y.Pattern || !sameSettings(x, y) {
+			return false
+		}
+		if x.AliasOf == "" && !valueEq(x.Value, y.Value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package mux
+
+import "strings"
+
+// SegmentMatchFn matches sep-delimited keys segment by segment. A pattern
+// segment "*" matches any single segment, and a pattern that matches the
+// leading segments of the input is an ancestor and matches as a fallback.
+// Deeper patterns always score higher than shallower ones; among patterns of
+// the same depth, literal segments beat wildcards, leftmost first.
+func SegmentMatchFn(sep string) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, score int) {
+		if pattern == "" || s == "" {
+			return false, 0
+		}
+		ps, ss := strings.Split(pattern, sep), strings.Split(s, sep)
+		if len(ps) > len(ss) {
+			return false, 0
+		}
+
+		literals := 0
+		for i, p := range ps {
+			switch {
+			case p == "*":
+			case p == ss[i]:
+				if i < 16 {
+					literals |= 1 << (15 - i)
+				}
+			default:
+				return false, 0
+			}
+		}
+		return true, len(ps)<<16 | literals
+	}
+}
+
+// DottedKeyMatch matches dotted configuration keys such as
+// "server.http.timeout" against patterns like "server.*.timeout", falling
+// back to the nearest configured ancestor ("server.http", then "server").
+var DottedKeyMatch = SegmentMatchFn(".")
+
+func NewDottedKeyMux() *Mux {
+	return New(Config{
+		Matcher: DottedKeyMatch,
+	})
+}
+
+// SKUTrim uppercases codes and strips surrounding space, so " elec-tv-55 "
+// becomes "ELEC-TV-55".
+var SKUTrim = func(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// SKUMatch matches hierarchical product codes such as "ELEC-TV-OLED55"
+// against patterns like "ELEC-TV-*", falling back level by level to the
+// nearest configured category ("ELEC-TV", then "ELEC").
+var SKUMatch = SegmentMatchFn("-")
+
+// NewSKUMux returns a Mux of dash-separated product codes, compared case
+// insensitively, e.g. to route products to pricing or fulfillment rules.
+func NewSKUMux() *Mux {
+	return New(Config{
+		TrimPattern: SKUTrim,
+		TrimString:  SKUTrim,
+		Matcher:     SKUMatch,
+	})
+}
@@ -0,0 +1,89 @@
+package mux
+
+import "strings"
+
+// EmailTrim reduces an address, possibly with a display name as in
+// "Alice <Alice+News@Example.COM>", to its lowercased address,
+// "alice+news@example.com".
+var EmailTrim = func(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '<'); i >= 0 {
+		if j := strings.IndexByte(s[i:], '>'); j >= 0 {
+			s = s[i+1 : i+j]
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// emailLocalScore matches the local part s of an address against the
+// local part of a pattern.
+func emailLocalScore(pattern, s string) (ok bool, score int) {
+	if pattern == "*" || pattern == "" {
+		return true, 0
+	}
+	if pattern == s {
+		return true, 3
+	}
+	user, _, tagged := strings.Cut(s, "+")
+	switch {
+	case !tagged:
+		return false, 0
+	case pattern == user:
+		return true, 2
+	case pattern == user+"+*":
+		return true, 1
+	}
+	return false, 0
+}
+
+// emailDomainScore matches the domain s of an address against the domain
+// of a pattern, label by label.
+func emailDomainScore(pattern, s string) (ok bool, score int) {
+	switch {
+	case pattern == "*":
+		return true, 0
+	case pattern == s:
+		return true, 1 << 8
+	case strings.HasPrefix(pattern, "*.") && strings.HasSuffix(s, pattern[1:]):
+		return true, 1 + strings.Count(pattern, ".")
+	}
+	return false, 0
+}
+
+// EmailMatch matches addresses against patterns such as
+// "alice@example.com", "*@example.com", "*@*.example.com" or
+// "support+*@*". Domains match by whole labels, so "*@example.com" does
+// not match "bob@notexample.com", and "*.example.com" only matches
+// subdomains. Plus addressing is understood: "alice@example.com" also
+// matches "alice+news@example.com", while "alice+*" only matches tagged
+// addresses. A pattern without "@", or with nothing before it, is a
+// domain. As with virtual mail tables, the more specific local part wins,
+// then the more specific domain.
+var EmailMatch = func(pattern, s string, index int) (ok bool, score int) {
+	plocal, pdomain, ok := strings.Cut(pattern, "@")
+	if !ok {
+		plocal, pdomain = "*", pattern
+	}
+	i := strings.LastIndexByte(s, '@')
+	if i <= 0 || i == len(s)-1 {
+		return false, 0
+	}
+	ok, local := emailLocalScore(plocal, s[:i])
+	if !ok {
+		return false, 0
+	}
+	ok, domain := emailDomainScore(pdomain, s[i+1:])
+	if !ok {
+		return false, 0
+	}
+	return true, local<<10 | domain
+}
+
+// NewEmailMux returns a Mux routing mail addresses, see EmailMatch.
+func NewEmailMux() *Mux {
+	return New(Config{
+		TrimPattern: EmailTrim,
+		TrimString:  EmailTrim,
+		Matcher:     EmailMatch,
+	})
+}
@@ -0,0 +1,52 @@
+package mux
+
+// Disable takes the entry registered under pattern out of matching,
+// keeping its registration, index, metadata and statistics until Enable,
+// e.g. as a kill switch. It reports whether there is such an entry. An
+// alias and its canonical pattern are disabled together.
+func (m *Mux) Disable(pattern string) bool {
+	return m.setDisabled(pattern, true)
+}
+
+// Enable puts the entry registered under pattern back into matching,
+// reporting whether there is one.
+func (m *Mux) Enable(pattern string) bool {
+	return m.setDisabled(pattern, false)
+}
+
+// SetHealthy marks the entry registered under pattern healthy or not,
+// reporting whether there is one. Unhealthy entries are skipped by matching
+// in favour of the next best match, e.g. by a circuit breaker taking a
+// failing backend out of selection. Unlike Disable it is cheap enough to
+// call on every request outcome.
+func (m *Mux) SetHealthy(pattern string, healthy bool) bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	_, e, ok := m.lookup(pattern)
+	if ok {
+		e.unhealthy.Store(!healthy)
+	}
+	return ok
+}
+
+// Healthy reports whether the entry registered under pattern exists and
+// is healthy.
+func (m *Mux) Healthy(pattern string) bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	_, e, ok := m.lookup(pattern)
+	return ok && !e.unhealthy.Load()
+}
+
+func (m *Mux) setDisabled(pattern string, disabled bool) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	_, e, ok := m.lookup(pattern)
+	if ok {
+		e.disabled = disabled
+	}
+	return ok
+}
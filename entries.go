@@ -0,0 +1,321 @@
+package mux
+
+import (
+	"sort"
+	"time"
+)
+
+// Entry is an exported copy of a registered entry.
+type Entry struct {
+	// Pattern is the key the entry is stored under, i.e. the pattern
+	// after trimming.
+	Pattern  string
+	Value    interface{}
+	Index    int
+	Weight   int
+	Priority int
+	Metadata map[string]interface{}
+	Tags     []string
+
+	// Budget is set for entries registered with MapWithBudget.
+	Budget time.Duration
+
+	// Rollout is the percentage of inputs served by entries registered
+	// with MapWithRollout, which have Rolled set. Rollout is ignored for
+	// other entries.
+	Rollout float64
+	Rolled  bool
+
+	// Stats is kept under Config.TrackStats.
+	Stats Stats
+
+	// AliasOf is the canonical pattern of entries registered with Alias,
+	// which share everything else with it.
+	AliasOf string
+
+	// Original is Pattern as registered, before trimming. Import treats
+	// an empty one as Pattern.
+	Original string
+
+	// Window is set for entries registered with MapWithWindow.
+	Window *Window
+
+	// Adjust is the score adjustment of AdjustScore.
+	Adjust int
+
+	// Disabled is set for entries taken out of matching with Disable.
+	Disabled bool
+
+	// Unhealthy is set for entries marked with SetHealthy. Being transient,
+	// it is ignored by Import.
+	Unhealthy bool
+
+	// Provider is set for entries registered with MapLazy. Value is nil
+	// until the provider has run.
+	Provider func() (interface{}, error)
+
+	// Matcher and Trimmer are set for entries registered with
+	// MapWithMatcher and MapWithTrimmer.
+	Matcher MatchFunc
+	Trimmer TrimFunc
+}
+
+func copyMeta(meta map[string]interface{}) map[string]interface{} {
+	if meta == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(meta))
+	for k, v := range meta {
+		c[k] = v
+	}
+	return c
+}
+
+func copyTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	return append([]string(nil), tags...)
+}
+
+func (e *entry) export(pattern string) Entry {
+	x := Entry{
+		Pattern:  pattern,
+		Value:    e.val,
+		Index:    e.index,
+		Weight:   e.weight,
+		Priority: e.priority,
+		Metadata: copyMeta(e.meta),
+		Tags:     copyTags(e.tags),
+		Budget:   e.budget,
+		Matcher:  e.matcher,
+		Trimmer:  e.trim,
+		Stats:    e.stats(),
+		Disabled: e.disabled,
+		Window:   e.window.export(),
+	}
+	x.Adjust = e.adjust
+	x.Unhealthy = e.unhealthy.Load()
+	if e.rolled {
+		x.Rollout, x.Rolled = e.rollout, true
+	}
+	if e.lazy != nil {
+		x.Value, _ = e.lazy.resolved()
+		x.Provider = e.lazy.f
+	}
+	return x
+}
+
+// SetMetadata replaces the metadata of the entry registered under pattern,
+// reporting whether there is one.
+func (m *Mux) SetMetadata(pattern string, meta map[string]interface{}) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	_, e, ok := m.lookup(pattern)
+	if ok {
+		e.meta = copyMeta(meta)
+	}
+	return ok
+}
+
+// SetTags replaces the tags of the entry registered under pattern,
+// reporting whether there is one.
+func (m *Mux) SetTags(pattern string, tags ...string) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	_, e, ok := m.lookup(pattern)
+	if ok {
+		e.tags = copyTags(tags)
+	}
+	return ok
+}
+
+// Lookup returns the entry registered under pattern.
+func (m *Mux) Lookup(pattern string) (Entry, bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	key, e, ok := m.lookup(pattern)
+	if !ok {
+		return Entry{}, false
+	}
+	x := e.export(key)
+	x.AliasOf = m.aliases[key]
+	x.Original = m.originalOf(key, e)
+	return x, true
+}
+
+// Entries returns a copy of every entry ordered by index, aliases after
+// their canonical entry.
+func (m *Mux) Entries() []Entry {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return m.entries()
+}
+
+func (m *Mux) entries() []Entry {
+	entries := make([]Entry, 0, len(m.m))
+	for p, e := range m.m {
+		x := e.export(p)
+		x.AliasOf = m.aliases[p]
+		x.Original = m.originalOf(p, e)
+		entries = append(entries, x)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := &entries[i], &entries[j]
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		// aliases share the index of their canonical entry
+		if (a.AliasOf == "") != (b.AliasOf == "") {
+			return a.AliasOf == ""
+		}
+		return a.Pattern < b.Pattern
+	})
+	return entries
+}
+
+// Import registers entries in one locked operation, replacing entries with
+// the same pattern. Patterns are used as keys as they are, without
+// trimming, so the output of Entries round-trips exactly. Entries keep
+// their index unless it is 0, in which case they get the next free one
+// in order; later registrations are numbered after the highest index
+// imported. A zero Weight becomes 1. Entries whose Window has a bad
+// schedule never match. Aliases are registered last, with
+// only their Pattern and AliasOf used.
+func (m *Mux) Import(entries []Entry) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.importEntries(entries)
+}
+
+func (m *Mux) importEntries(entries []Entry) {
+	for _, in := range entries {
+		if in.Index > m.index {
+			m.index = in.Index
+		}
+	}
+	for _, in := range entries {
+		if in.AliasOf != "" {
+			continue
+		}
+		e, ok := m.m[in.Pattern]
+		if _, alias := m.aliases[in.Pattern]; ok && alias {
+			m.remove(in.Pattern, e)
+			ok = false
+		}
+		switch {
+		case ok:
+			if e.trim != nil {
+				m.ownTrims--
+			}
+			if in.Index != 0 {
+				e.index = in.Index
+			}
+			m.order = nil
+		case in.Index != 0:
+			e = m.add(in.Pattern, in.Index)
+			m.order = nil
+		default:
+			e = m.insert(in.Pattern)
+		}
+		if e == nil {
+			continue
+		}
+		m.setValue(in.Pattern, e, in.Value)
+		e.priority = in.Priority
+		e.weight = in.Weight
+		if e.weight == 0 {
+			e.weight = 1
+		}
+		e.meta, e.tags = copyMeta(in.Metadata), copyTags(in.Tags)
+		e.budget = in.Budget
+		e.rollout, e.rolled = 0, in.Rolled
+		if in.Rolled {
+			e.rollout = in.Rollout
+		}
+		e.disabled = in.Disabled
+		e.adjust = in.Adjust
+		e.original = ""
+		if in.Original != in.Pattern {
+			e.original = in.Original
+		}
+		e.window = nil
+		if in.Window != nil {
+			e.window = newWindow(in.Window)
+		}
+		if in.Provider != nil {
+			e.val, e.lazy = nil, &lazyValue{f: in.Provider}
+		}
+		m.setEntryMatcher(e, in.Matcher)
+		e.trim = in.Trimmer
+		if e.trim != nil {
+			m.ownTrims++
+		}
+		e.filter = nil
+		if e.matcher == nil && m.prefilter != nil {
+			e.filter = m.prefilter(in.Pattern)
+		}
+	}
+	for _, in := range entries {
+		if e, ok := m.m[in.AliasOf]; ok && in.AliasOf != "" && e.trim == nil {
+			orig := in.Original
+			if orig == "" {
+				orig = in.Pattern
+			}
+			m.alias(in.Pattern, in.AliasOf, e, orig)
+		}
+	}
+}
+
+// FromMap maps every pattern of vals to its value in one locked
+// operation, like MapAll, assigning indexes deterministically: patterns
+// listed in order first, in that order, then the others sorted. Patterns
+// of order missing from vals are ignored.
+func (m *Mux) FromMap(vals map[string]interface{}, order ...string) {
+	patterns := make([]string, 0, len(vals))
+	listed := make(map[string]bool, len(order))
+	for _, p := range order {
+		if _, ok := vals[p]; ok && !listed[p] {
+			listed[p] = true
+			patterns = append(patterns, p)
+		}
+	}
+	rest := len(patterns)
+	for p := range vals {
+		if !listed[p] {
+			patterns = append(patterns, p)
+		}
+	}
+	sort.Strings(patterns[rest:])
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, pattern := range patterns {
+		for _, p := range m.expand(pattern) {
+			m.mapEntry(p, vals[pattern])
+		}
+	}
+}
+
+// ToMap returns the value of every entry, aliases included, by pattern.
+// Lazy values not provided yet are nil.
+func (m *Mux) ToMap() map[string]interface{} {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	vals := make(map[string]interface{}, len(m.m))
+	for p, e := range m.m {
+		v := e.val
+		if e.lazy != nil {
+			v, _ = e.lazy.resolved()
+		}
+		vals[p] = v
+	}
+	return vals
+}
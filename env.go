@@ -0,0 +1,117 @@
+package mux
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvRoutePrefix is the default prefix of the variables read by LoadEnv.
+const EnvRoutePrefix = "MUX_ROUTE_"
+
+// EnvRouteSeparator separates the pattern from the value in variables read
+// by LoadEnv.
+const EnvRouteSeparator = "=>"
+
+type route struct {
+	pattern string
+	val     interface{}
+}
+
+// mapRoutes maps every route in one locked operation.
+func (m *Mux) mapRoutes(routes []route) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, r := range routes {
+		for _, p := range m.expand(r.pattern) {
+			m.mapEntry(p, r.val)
+		}
+	}
+}
+
+// LoadEnv maps a route for every environment variable whose name starts
+// with prefix (EnvRoutePrefix when empty). Each value holds a pattern and a
+// string value separated by EnvRouteSeparator, e.g.
+//
+//	MUX_ROUTE_USERS="/api/users/ => users-service"
+//
+// Variables are mapped in order of their names. Nothing is mapped if any
+// variable is malformed.
+func (m *Mux) LoadEnv(prefix string) error {
+	if prefix == "" {
+		prefix = EnvRoutePrefix
+	}
+
+	var names []string
+	values := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+			values[name] = value
+		}
+	}
+	sort.Strings(names)
+
+	routes := make([]route, 0, len(names))
+	for _, name := range names {
+		pattern, val, ok := strings.Cut(values[name], EnvRouteSeparator)
+		if !ok {
+			return fmt.Errorf("mux: %s: missing %q between pattern and value", name, EnvRouteSeparator)
+		}
+		routes = append(routes, route{strings.TrimSpace(pattern), strings.TrimSpace(val)})
+	}
+	m.mapRoutes(routes)
+	return nil
+}
+
+// LoadEnvJSON maps the routes held by the environment variable name as a
+// JSON object from patterns to values, e.g.
+//
+//	MUX_ROUTES='{"/api/users/": "users-service", "/": "frontend"}'
+//
+// Routes are mapped in document order, and values keep their JSON types.
+// An unset variable maps nothing.
+func (m *Mux) LoadEnvJSON(name string) error {
+	doc, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	routes, err := decodeRoutes(doc)
+	if err != nil {
+		return fmt.Errorf("mux: %s: %w", name, err)
+	}
+	m.mapRoutes(routes)
+	return nil
+}
+
+// decodeRoutes decodes a JSON object from patterns to values, keeping
+// the order of its keys.
+func decodeRoutes(doc string) ([]route, error) {
+	dec := json.NewDecoder(strings.NewReader(doc))
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var routes []route
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var val interface{}
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route{t.(string), val})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
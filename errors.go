@@ -0,0 +1,97 @@
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoMatch is returned, wrapped in a *MatchError, when nothing
+	// matches an input.
+	ErrNoMatch = errors.New("mux: no match")
+
+	// ErrAmbiguous is returned, wrapped in a *MatchError listing the tied
+	// patterns, when several entries share the best match.
+	ErrAmbiguous = errors.New("mux: ambiguous match")
+
+	// ErrBadPattern is matched by every *PatternError.
+	ErrBadPattern = errors.New("mux: bad pattern")
+
+	ErrFrozen = errors.New("mux: matcher and trimmers cannot change once entries exist")
+
+	// ErrFull is returned by TryMap when a table capped by
+	// Config.MaxEntries with EvictReject has no room.
+	ErrFull = errors.New("mux: too many entries")
+
+	// ErrTruncated is returned with the results of MatchAll calls capped by
+	// Config.MaxResults.
+	ErrTruncated = errors.New("mux: too many results")
+)
+
+// MatchError describes a failed match. Err is ErrNoMatch or ErrAmbiguous.
+type MatchError struct {
+	Input string
+
+	// Patterns are the competing patterns of an ambiguous match.
+	Patterns []string
+
+	Err error
+}
+
+func (e *MatchError) Error() string {
+	if len(e.Patterns) > 0 {
+		return fmt.Sprintf("%v for %q between %s", e.Err, e.Input, strings.Join(e.Patterns, ", "))
+	}
+	return fmt.Sprintf("%v for %q", e.Err, e.Input)
+}
+
+func (e *MatchError) Unwrap() error {
+	return e.Err
+}
+
+// TrimError describes an input rejected by Config.TrimStringErr.
+type TrimError struct {
+	Input string
+	Err   error
+}
+
+func (e *TrimError) Error() string {
+	return fmt.Sprintf("mux: rejected input %q: %v", e.Input, e.Err)
+}
+
+func (e *TrimError) Unwrap() error {
+	return e.Err
+}
+
+// PatternError describes a pattern that cannot be registered.
+type PatternError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("mux: bad pattern %q: %v", e.Pattern, e.Err)
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PatternError) Is(target error) bool {
+	return target == ErrBadPattern
+}
+
+// SpecError describes a spec string that cannot be parsed.
+type SpecError struct {
+	Spec string
+	Err  error
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("mux: bad spec %q: %v", e.Spec, e.Err)
+}
+
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
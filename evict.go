@@ -0,0 +1,39 @@
+package mux
+
+import "reflect"
+
+// sameValue reports whether a and b are the same value, without panicking
+// on uncomparable types, which are never the same.
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// evict reports the value of the entry e stored under key as gone.
+func (m *Mux) evict(key string, e *entry) {
+	if m.onEvict == nil {
+		return
+	}
+	v := e.val
+	if e.lazy != nil {
+		var ok bool
+		if v, ok = e.lazy.resolved(); !ok {
+			return
+		}
+	}
+	if v != nil {
+		m.onEvict(key, v)
+	}
+}
+
+// setValue sets the value of the entry e stored under key, evicting the
+// old one when it differs.
+func (m *Mux) setValue(key string, e *entry, val interface{}) {
+	if e.lazy != nil || !sameValue(e.val, val) {
+		m.evict(key, e)
+	}
+	e.val, e.lazy = val, nil
+}
@@ -0,0 +1,77 @@
+package mux
+
+// EvictionPolicy chooses the entry evicted from a table capped by
+// Config.MaxEntries.
+type EvictionPolicy int
+
+const (
+	// EvictLRU evicts the entry that has gone longest without matching.
+	EvictLRU EvictionPolicy = iota
+
+	// EvictLFU evicts the entry that matched least often.
+	EvictLFU
+
+	// EvictFIFO evicts the oldest entry.
+	EvictFIFO
+
+	// EvictReject evicts nothing and drops new patterns instead.
+	EvictReject
+)
+
+// size returns the number of entries, aliases aside.
+func (m *Mux) size() int {
+	return len(m.m) - len(m.aliases)
+}
+
+func (m *Mux) full() bool {
+	return m.maxEntries > 0 && m.size() >= m.maxEntries
+}
+
+// makeRoom evicts one entry under the eviction policy, reporting false
+// under EvictReject.
+func (m *Mux) makeRoom() bool {
+	if m.eviction == EvictReject {
+		return false
+	}
+
+	var victim string
+	var ve *entry
+	for p, e := range m.m {
+		if _, alias := m.aliases[p]; alias {
+			continue
+		}
+		if ve == nil || m.evictsBefore(e, ve) {
+			victim, ve = p, e
+		}
+	}
+	if ve != nil {
+		m.remove(victim, ve)
+	}
+	return true
+}
+
+// evictsBefore reports whether a is evicted before b, the older entry
+// going first on ties.
+func (m *Mux) evictsBefore(a, b *entry) bool {
+	switch m.eviction {
+	case EvictLRU:
+		la, lb := a.lastUse(), b.lastUse()
+		if la != lb {
+			return la < lb
+		}
+	case EvictLFU:
+		ha, hb := a.hits.Load(), b.hits.Load()
+		if ha != hb {
+			return ha < hb
+		}
+	}
+	return a.index < b.index
+}
+
+// lastUse returns when e last matched, or was created if it never did.
+func (e *entry) lastUse() int64 {
+	if t := e.lastHit.Load(); t != 0 {
+		return t
+	}
+	return e.created
+}
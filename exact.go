@@ -0,0 +1,60 @@
+package mux
+
+import "reflect"
+
+func sameFunc(f, g interface{}) bool {
+	return reflect.ValueOf(f).Pointer() == reflect.ValueOf(g).Pointer()
+}
+
+// updateExact checks whether the Mux matches with StrictMatch on untrimmed
+// inputs and nothing else observes matching, so Match can be a single map
+// read, and whether it matches with PhonePrefixMatch, so Match need only
+// visit the prefixes of its input. The caller must hold the write lock or
+// own the Mux.
+func (m *Mux) updateExact() {
+	m.exact = sameFunc(m.matcher, StrictMatch) && m.ctxMatcher == nil && sameFunc(m.trimString, NoTrim) && m.trimStringErr == nil &&
+		m.hook == nil && m.logger == nil && m.recent == nil && !m.profile &&
+		m.fallback == nil && m.minScore == 0 && !m.intern
+	m.prefixKeys = sameFunc(m.matcher, PhonePrefixMatch) && m.ctxMatcher == nil
+}
+
+func (m *Mux) setEntryMatcher(e *entry, f MatchFunc) {
+	switch {
+	case e.matcher == nil && f != nil:
+		m.ownMatchers++
+	case e.matcher != nil && f == nil:
+		m.ownMatchers--
+	}
+	e.matcher = f
+}
+
+// matchExact looks s up directly when the Mux allows it, reporting false in
+// ok when it does not.
+func (m *Mux) matchExact(s string) (r MatchResult, found, ok bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if !m.exact || m.ownMatchers > 0 || m.ownTrims > 0 {
+		return r, false, false
+	}
+	e, found := m.m[s]
+	if found && (e.rolled || e.window != nil) {
+		return r, false, false
+	}
+	if !found || e.disabled || e.unhealthy.Load() || (e.filter != nil && !e.filter(s)) {
+		return r, false, true
+	}
+	m.hit(e)
+	r = MatchResult{
+		Pattern:  s,
+		Value:    e.val,
+		Score:    e.adjust,
+		Index:    e.index,
+		Priority: e.priority,
+		Original: m.originalOf(s, e),
+	}
+	if e.lazy != nil {
+		r.Value = m.valueOf(s, e)
+	}
+	return r, true, true
+}
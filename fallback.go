@@ -0,0 +1,30 @@
+package mux
+
+import "strings"
+
+// FallbackFunc returns the input to retry when s matches nothing, or false
+// when there is none.
+type FallbackFunc func(s string) (fallback string, ok bool)
+
+// PathParent falls back from a path to its parent directory, so /a/b/c
+// tries /a/b/, then /a/, then /.
+var PathParent = func(s string) (parent string, ok bool) {
+	s = strings.TrimSuffix(s, "/")
+	i := strings.LastIndexByte(s, '/')
+	if i < 0 {
+		return "", false
+	}
+	return s[:i+1], true
+}
+
+// NewTreeMux returns a Mux matching paths exactly, falling back to the
+// nearest registered ancestor directory, as for config and permission
+// trees.
+func NewTreeMux() *Mux {
+	return New(Config{
+		TrimPattern: PathTrim,
+		TrimString:  PathTrim,
+		Matcher:     StrictMatch,
+		Fallback:    PathParent,
+	})
+}
@@ -0,0 +1,84 @@
+package mux
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileTrim converts s to a clean slash-separated path.
+var FileTrim = func(s string) string {
+	if s == "" {
+		return s
+	}
+	return path.Clean(filepath.ToSlash(s))
+}
+
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, `*?[\`)
+}
+
+func globLiterals(s string) (n int) {
+	for i := 0; i < len(s); i++ {
+		if !strings.ContainsRune(`*?[]\`, rune(s[i])) {
+			n++
+		}
+	}
+	return
+}
+
+// FileMatch matches file paths against one of the following pattern forms,
+// listed from most to least specific:
+//
+//	"docs/README.md"   exact path, or exact basename when it has no slash
+//	"img/*/thumb.png"  glob over the whole path
+//	"*.tar.gz"         glob over the basename
+//	".jpg,.JPEG,.png"  extension set, compared case-insensitively
+//	"uploads/tmp/"     directory prefix
+//
+// Within a form, longer literal patterns score higher.
+var FileMatch = func(pattern, s string, index int) (ok bool, score int) {
+	if pattern == "" || s == "" {
+		return false, 0
+	}
+	base := path.Base(s)
+
+	switch {
+	case strings.HasSuffix(pattern, "/"):
+		dir := strings.TrimPrefix(pattern, "./")
+		return strings.HasPrefix(s+"/", dir), len(dir)
+
+	case pattern[0] == '.' && !hasGlobMeta(pattern) && !strings.Contains(pattern, "/"):
+		lower := strings.ToLower(base)
+		for _, ext := range strings.Split(pattern, ",") {
+			ext = strings.ToLower(strings.TrimSpace(ext))
+			if len(ext) > 1 && strings.HasSuffix(lower, ext) {
+				if 1000+len(ext) > score {
+					ok, score = true, 1000+len(ext)
+				}
+			}
+		}
+		return
+
+	case strings.Contains(pattern, "/"):
+		if !hasGlobMeta(pattern) {
+			return path.Clean(pattern) == s, 4000 + len(pattern)
+		}
+		ok, _ = path.Match(pattern, s)
+		return ok, 3000 + globLiterals(pattern)
+
+	default:
+		if !hasGlobMeta(pattern) {
+			return pattern == base, 4000 + len(pattern)
+		}
+		ok, _ = path.Match(pattern, base)
+		return ok, 2000 + globLiterals(pattern)
+	}
+}
+
+func NewFileMux() *Mux {
+	return New(Config{
+		TrimString: FileTrim,
+		Matcher:    FileMatch,
+	})
+}
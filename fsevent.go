@@ -0,0 +1,150 @@
+package mux
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FSOp is a set of file system operations. The values are those of
+// fsnotify.Op, so an fsnotify event converts with FSOp(ev.Op).
+type FSOp uint32
+
+const (
+	FSCreate FSOp = 1 << iota
+	FSWrite
+	FSRemove
+	FSRename
+	FSChmod
+
+	// FSAll is every operation.
+	FSAll FSOp = 1<<iota - 1
+)
+
+func (op FSOp) String() string {
+	var names []string
+	for i, name := range []string{"CREATE", "WRITE", "REMOVE", "RENAME", "CHMOD"} {
+		if op&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "0"
+	}
+	return strings.Join(names, "|")
+}
+
+// FSEvent is a file system event, such as one of fsnotify:
+//
+//	mux.FSEvent{Name: ev.Name, Op: mux.FSOp(ev.Op)}
+type FSEvent struct {
+	Name string
+	Op   FSOp
+}
+
+type fsRoute struct {
+	ops FSOp
+	h   func(FSEvent)
+}
+
+// FSRouter dispatches file system events by path to handlers registered
+// with the patterns of FileMatch, e.g. a directory tree "src/", a glob
+// "cmd/*/main.go" or an extension set ".go,.mod".
+type FSRouter struct {
+	root string
+	m    *Mux
+
+	mtx    sync.Mutex
+	routes map[string][]fsRoute
+
+	// Unhandled, when set, is called with the events no handler takes.
+	Unhandled func(FSEvent)
+}
+
+// NewFSRouter returns an FSRouter matching the names of events relative
+// to root, or as they are when root is empty or they lie outside it.
+func NewFSRouter(root string) *FSRouter {
+	return &FSRouter{
+		root: root,
+		m: New(Config{
+			TrimString:     FileTrim,
+			Matcher:        FileMatch,
+			OrderedResults: true,
+		}),
+		routes: make(map[string][]fsRoute),
+	}
+}
+
+// Handle registers h for the events of ops, or of every operation when ops
+// is 0, on paths matching pattern. Handlers of one pattern add up.
+func (r *FSRouter) Handle(pattern string, ops FSOp, h func(FSEvent)) {
+	if ops == 0 {
+		ops = FSAll
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// copied, matches running read the old slice
+	old := r.routes[pattern]
+	routes := append(old[:len(old):len(old)], fsRoute{ops, h})
+	r.routes[pattern] = routes
+	r.m.Map(pattern, routes)
+}
+
+// Remove removes the handlers of pattern.
+func (r *FSRouter) Remove(pattern string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	delete(r.routes, pattern)
+	r.m.Delete(pattern)
+}
+
+func (r *FSRouter) relative(name string) string {
+	if r.root == "" {
+		return name
+	}
+	rel, err := filepath.Rel(r.root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return name
+	}
+	return rel
+}
+
+// Dispatch calls the handlers for ev of the most specific pattern matching
+// its path that has any, reporting whether there was one.
+func (r *FSRouter) Dispatch(ev FSEvent) bool {
+	for _, v := range r.m.MatchAll(r.relative(ev.Name)) {
+		handled := false
+		for _, rt := range v.([]fsRoute) {
+			if rt.ops&ev.Op != 0 {
+				rt.h(ev)
+				handled = true
+			}
+		}
+		if handled {
+			return true
+		}
+	}
+	if r.Unhandled != nil {
+		r.Unhandled(ev)
+	}
+	return false
+}
+
+// Run dispatches the events received from events until it is closed or
+// ctx is done.
+func (r *FSRouter) Run(ctx context.Context, events <-chan FSEvent) error {
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			r.Dispatch(ev)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
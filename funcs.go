@@ -0,0 +1,123 @@
+package mux
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	namedMtx sync.RWMutex
+	matchers = map[string]MatchFunc{
+		"strict":    StrictMatch,
+		"path":      PathMatch,
+		"prefix":    PrefixMatch,
+		"suffix":    SuffixMatch,
+		"regex":     RegexMatch,
+		"file":      FileMatch,
+		"gitignore": GitignoreMatch,
+		"label":     LabelSelectorMatch,
+		"lang":      LangMatch,
+		"mime":      MIMEMatch,
+		"range":     RangeMatch,
+		"schedule":  ScheduleMatch,
+		"dotted":    DottedKeyMatch,
+		"typeurl":   TypeURLMatch,
+		"method":    MethodMatch,
+		"phone":     PhonePrefixMatch,
+		"sku":       SKUMatch,
+		"email":     EmailMatch,
+		"url":       URLMatch,
+	}
+	trimmers = map[string]TrimFunc{
+		"none":      NoTrim,
+		"path":      PathTrim,
+		"file":      FileTrim,
+		"asset":     FingerprintTrim,
+		"gitignore": GitignoreTrim,
+		"lang":      LangTrim,
+		"mime":      MIMETrim,
+		"typeurl":   TypeURLTrim,
+		"phone":     PhoneTrim,
+		"sku":       SKUTrim,
+		"email":     EmailTrim,
+		"url":       URLTrim,
+		"lowercase": strings.ToLower,
+		"space":     strings.TrimSpace,
+	}
+)
+
+// RegisterMatcher makes f available by name through LookupMatcher, so
+// config files and tools can refer to it. The package's own matchers are
+// registered under short names such as "path" and "regex". Like Register,
+// it panics when name is already registered or f is nil.
+func RegisterMatcher(name string, f MatchFunc) {
+	namedMtx.Lock()
+	defer namedMtx.Unlock()
+
+	if f == nil {
+		panic("mux: RegisterMatcher of nil matcher " + name)
+	}
+	if _, ok := matchers[name]; ok {
+		panic("mux: RegisterMatcher called twice for " + name)
+	}
+	matchers[name] = f
+}
+
+// RegisterTrimmer is RegisterMatcher for trimmers, which are registered
+// under names such as "none" and "path".
+func RegisterTrimmer(name string, f TrimFunc) {
+	namedMtx.Lock()
+	defer namedMtx.Unlock()
+
+	if f == nil {
+		panic("mux: RegisterTrimmer of nil trimmer " + name)
+	}
+	if _, ok := trimmers[name]; ok {
+		panic("mux: RegisterTrimmer called twice for " + name)
+	}
+	trimmers[name] = f
+}
+
+// LookupMatcher returns the matcher registered under name.
+func LookupMatcher(name string) (MatchFunc, bool) {
+	namedMtx.RLock()
+	defer namedMtx.RUnlock()
+
+	f, ok := matchers[name]
+	return f, ok
+}
+
+// LookupTrimmer returns the trimmer registered under name.
+func LookupTrimmer(name string) (TrimFunc, bool) {
+	namedMtx.RLock()
+	defer namedMtx.RUnlock()
+
+	f, ok := trimmers[name]
+	return f, ok
+}
+
+// Matchers returns the sorted names of the registered matchers.
+func Matchers() []string {
+	namedMtx.RLock()
+	defer namedMtx.RUnlock()
+
+	return sortedNames(matchers)
+}
+
+// Trimmers returns the sorted names of the registered trimmers.
+func Trimmers() []string {
+	namedMtx.RLock()
+	defer namedMtx.RUnlock()
+
+	return sortedNames(trimmers)
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
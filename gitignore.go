@@ -0,0 +1,139 @@
+package mux
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+type gitignoreRule struct {
+	re      *regexp.Regexp
+	dirOnly bool
+	negate  bool
+}
+
+var gitignoreRules patternCache[*gitignoreRule] // nil for comments
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			b.WriteString(`(?:.*/)?`)
+			i += 2
+		case strings.HasPrefix(glob[i:], "**") && i+2 == len(glob) && (i == 0 || glob[i-1] == '/'):
+			b.WriteString(`.*`)
+			i++
+		case c == '*':
+			b.WriteString(`[^/]*`)
+		case c == '?':
+			b.WriteString(`[^/]`)
+		case c == '[':
+			j := strings.IndexByte(glob[i+1:], ']')
+			if j < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += j + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+func parseGitignore(pattern string) *gitignoreRule {
+	return gitignoreRules.get(pattern, compileGitignore)
+}
+
+func compileGitignore(pattern string) *gitignoreRule {
+	var rule *gitignoreRule
+	p := strings.TrimRight(pattern, " \t")
+	if p != "" && p[0] != '#' {
+		rule = &gitignoreRule{}
+		if p[0] == '!' {
+			rule.negate, p = true, p[1:]
+		} else if strings.HasPrefix(p, `\!`) || strings.HasPrefix(p, `\#`) {
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			rule.dirOnly, p = true, strings.TrimRight(p, "/")
+		}
+
+		expr := "^"
+		if !strings.Contains(p, "/") {
+			expr += `(?:.*/)?`
+		}
+		expr += globToRegexp(strings.TrimPrefix(p, "/")) + "$"
+		re, err := regexp.Compile(expr)
+		if err != nil || p == "" {
+			rule = nil
+		} else {
+			rule.re = re
+		}
+	}
+	return rule
+}
+
+// GitignoreTrim cleans a slash-separated path relative to the repository
+// root, keeping a trailing slash that marks a directory.
+var GitignoreTrim = func(s string) string {
+	dir := strings.HasSuffix(s, "/")
+	s = strings.TrimPrefix(path.Clean("/"+s), "/")
+	if dir && s != "" {
+		s += "/"
+	}
+	return s
+}
+
+// GitignoreMatch matches paths against .gitignore patterns, including "**",
+// anchoring with a leading or inner slash, directory-only patterns with a
+// trailing slash and "!" negation. Directory inputs end in a slash, and a
+// pattern matching any parent directory matches everything below it.
+// Blank patterns and comments never match.
+//
+// As in a .gitignore file the last registered matching pattern wins, so the
+// score is the entry index; use Ignored to apply negation.
+var GitignoreMatch = func(pattern, s string, index int) (ok bool, score int) {
+	rule := parseGitignore(pattern)
+	if rule == nil || s == "" {
+		return false, 0
+	}
+
+	isDir := strings.HasSuffix(s, "/")
+	p := strings.TrimSuffix(s, "/")
+	for {
+		if (isDir || !rule.dirOnly) && rule.re.MatchString(p) {
+			return true, index
+		}
+		i := strings.LastIndexByte(p, '/')
+		if i < 0 {
+			return false, 0
+		}
+		p, isDir = p[:i], true
+	}
+}
+
+func NewGitignoreMux() *Mux {
+	return New(Config{
+		TrimString: GitignoreTrim,
+		Matcher:    GitignoreMatch,
+	})
+}
+
+// Ignored reports whether the last pattern matching p is a non-negated one,
+// i.e. whether p is excluded by a Mux built with GitignoreMatch.
+func (m *Mux) Ignored(p string) bool {
+	_, pattern := m.MatchWithPattern(p)
+	rule := parseGitignore(pattern)
+	return rule != nil && !rule.negate
+}
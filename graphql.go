@@ -0,0 +1,241 @@
+package mux
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+)
+
+// GraphQLOperation identifies an operation of a GraphQL document. Type is
+// "query", "mutation" or "subscription". Name is empty for anonymous
+// operations.
+type GraphQLOperation struct {
+	Type string
+	Name string
+}
+
+// GraphQLRequest is the body of a GraphQL request over HTTP.
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+var errGraphQLSyntax = errors.New("mux: malformed GraphQL document")
+
+// graphQLOperations lists the operations of doc. It only tokenizes the
+// document as far as needed to find the operation definitions at its top
+// level, skipping strings, comments, fragments and selection sets.
+func graphQLOperations(doc string) ([]GraphQLOperation, error) {
+	var (
+		ops      []GraphQLOperation
+		depth    int
+		atDef    = true // at the start of a definition
+		wantName bool   // after an operation keyword
+	)
+	for i := 0; i < len(doc); {
+		c := doc[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(doc) && doc[i] != '\n' && doc[i] != '\r' {
+				i++
+			}
+		case c == '"':
+			n, ok := graphQLString(doc[i:])
+			if !ok {
+				return nil, errGraphQLSyntax
+			}
+			i += n
+			wantName = false
+		case c == '{' || c == '(' || c == '[':
+			if c == '{' && depth == 0 && atDef {
+				// the query shorthand
+				ops = append(ops, GraphQLOperation{Type: "query"})
+			}
+			depth++
+			atDef, wantName = false, false
+			i++
+		case c == '}' || c == ')' || c == ']':
+			if depth--; depth < 0 {
+				return nil, errGraphQLSyntax
+			}
+			if depth == 0 && c == '}' {
+				atDef = true
+			}
+			i++
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			j := i + 1
+			for j < len(doc) && (doc[j] == '_' || 'a' <= doc[j] && doc[j] <= 'z' || 'A' <= doc[j] && doc[j] <= 'Z' || '0' <= doc[j] && doc[j] <= '9') {
+				j++
+			}
+			name := doc[i:j]
+			switch {
+			case wantName:
+				ops[len(ops)-1].Name = name
+				wantName = false
+			case depth == 0 && atDef:
+				if name == "query" || name == "mutation" || name == "subscription" {
+					ops = append(ops, GraphQLOperation{Type: name})
+					wantName = true
+				}
+				atDef = false
+			}
+			i = j
+		default:
+			wantName = false
+			i++
+		}
+	}
+	if depth != 0 {
+		return nil, errGraphQLSyntax
+	}
+	return ops, nil
+}
+
+// graphQLString returns the length of the string or block string
+// starting s.
+func graphQLString(s string) (n int, ok bool) {
+	if len(s) >= 3 && s[:3] == `"""` {
+		for i := 3; i+3 <= len(s); i++ {
+			switch {
+			case s[i] == '\\' && i+4 <= len(s) && s[i+1:i+4] == `"""`:
+				i += 3
+			case s[i:i+3] == `"""`:
+				return i + 3, true
+			}
+		}
+		return 0, false
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, true
+		case '\n', '\r':
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// ParseGraphQLOperation returns the operation of the document query to
+// execute: the one named operationName, or the only one when
+// operationName is empty.
+func ParseGraphQLOperation(query, operationName string) (GraphQLOperation, error) {
+	ops, err := graphQLOperations(query)
+	if err != nil {
+		return GraphQLOperation{}, err
+	}
+	if operationName == "" {
+		if len(ops) != 1 {
+			return GraphQLOperation{}, fmt.Errorf("mux: GraphQL document has %d operations and no operation name", len(ops))
+		}
+		return ops[0], nil
+	}
+	for _, op := range ops {
+		if op.Name == operationName {
+			return op, nil
+		}
+	}
+	return GraphQLOperation{}, fmt.Errorf("mux: GraphQL operation %q not found", operationName)
+}
+
+// anyMatch matches equal strings, or anything for the pattern "*".
+var anyMatch = func(pattern, s string, index int) (ok bool, score int) {
+	if pattern == "*" {
+		return true, 0
+	}
+	return pattern == s, 1
+}
+
+// globMatch matches s against the path.Match glob pattern, exact patterns
+// scoring highest, then globs with more literals.
+var globMatch = func(pattern, s string, index int) (ok bool, score int) {
+	if !hasGlobMeta(pattern) {
+		return pattern == s, 1 << 16
+	}
+	ok, _ = path.Match(pattern, s)
+	return ok, globLiterals(pattern)
+}
+
+// GraphQLRouter routes GraphQL operations by type and name to backends,
+// e.g. the subgraphs of a gateway. Operation names dominate: a route for
+// "query GetUser" beats one for any "GetUser" operation, which beats one
+// for "query Get*".
+type GraphQLRouter struct {
+	m *Mux
+}
+
+// NewGraphQLRouter returns an empty GraphQLRouter.
+func NewGraphQLRouter() *GraphQLRouter {
+	return &GraphQLRouter{
+		m: NewCompositeMux([]Dimension{
+			{Matcher: anyMatch},
+			{Matcher: globMatch},
+		}, WeightedScoresFn(1, 2)),
+	}
+}
+
+// Handle routes the operations of opType, or of any type when opType is
+// "*", with a name matching the glob name to backend. The name "*" also
+// matches anonymous operations.
+func (g *GraphQLRouter) Handle(opType, name string, backend interface{}) {
+	g.m.Map(JoinKey(opType, name), backend)
+}
+
+// Route returns the backend of op.
+func (g *GraphQLRouter) Route(op GraphQLOperation) (backend interface{}, ok bool) {
+	res, err := g.m.MatchResult(JoinKey(op.Type, op.Name))
+	return res.Value, err == nil
+}
+
+// RouteRequest returns the backend of the operation of the GraphQL request
+// body, with the decoded request and operation.
+func (g *GraphQLRouter) RouteRequest(body []byte) (backend interface{}, req GraphQLRequest, op GraphQLOperation, err error) {
+	if err = json.Unmarshal(body, &req); err != nil {
+		return nil, req, op, fmt.Errorf("mux: decoding GraphQL request: %w", err)
+	}
+	if op, err = ParseGraphQLOperation(req.Query, req.OperationName); err != nil {
+		return nil, req, op, err
+	}
+	res, err := g.m.MatchResult(JoinKey(op.Type, op.Name))
+	if err != nil {
+		return nil, req, op, err
+	}
+	return res.Value, req, op, nil
+}
+
+// ServeHTTP serves GraphQL POST requests with the http.Handler backend of
+// their operation, see MatchHandler, which can read the body again.
+func (g *GraphQLRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	backend, _, _, err := g.RouteRequest(body)
+	var merr *MatchError
+	switch {
+	case errors.As(err, &merr):
+		http.NotFound(w, r)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h, ok := asHandler(backend)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
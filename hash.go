@@ -0,0 +1,60 @@
+package mux
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+func hash32(s string) uint32 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	v := h.Sum64()
+	// fnv alone spreads near-identical keys poorly; finish with fmix64.
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	v *= 0xc4ceb9fe1a85ec53
+	v ^= v >> 33
+	return uint32(v>>32) ^ uint32(v)
+}
+
+// HashRingMatchFn returns a matcher treating each pattern as a shard id with
+// vnodes virtual nodes on a consistent hash ring. Every pattern matches every
+// key, and the pattern owning the first virtual node clockwise from the key's
+// hash scores highest, so Match returns the key's owner. Adding or removing a
+// shard only moves the keys that shard gains or loses.
+func HashRingMatchFn(vnodes int) MatchFunc {
+	if vnodes < 1 {
+		vnodes = 1
+	}
+
+	var ring patternCache[[]uint32] // sorted virtual nodes of each pattern
+	points := func(pattern string) []uint32 {
+		return ring.get(pattern, func(pattern string) []uint32 {
+			p := make([]uint32, vnodes)
+			for i := range p {
+				p[i] = hash32(pattern + "#" + strconv.Itoa(i))
+			}
+			sort.Slice(p, func(i, j int) bool { return p[i] < p[j] })
+			return p
+		})
+	}
+
+	return func(pattern, s string, index int) (ok bool, score int) {
+		p := points(pattern)
+		h := hash32(s)
+		i := sort.Search(len(p), func(i int) bool { return p[i] >= h })
+		if i == len(p) {
+			i = 0
+		}
+		// uint32 arithmetic wraps around the ring; halve so it fits any int.
+		return true, -int((p[i] - h) >> 1)
+	}
+}
+
+func NewHashRingMux(vnodes int) *Mux {
+	return New(Config{
+		Matcher: HashRingMatchFn(vnodes),
+	})
+}
@@ -0,0 +1,47 @@
+package mux
+
+import (
+	"context"
+	"time"
+)
+
+// MatchInfo describes a completed Match or MatchAll call.
+type MatchInfo struct {
+	// Input is the input as passed in, before trimming.
+	Input string
+
+	// All is set for MatchAll calls.
+	All bool
+
+	// Pattern and Score describe the winner of a Match call, or the best
+	// result of a ranked (paged) MatchAll call.
+	Pattern string
+	Score   int
+
+	Matched bool
+
+	// Candidates is the number of results of a MatchAll call, and 1 for a
+	// Match call that matched.
+	Candidates int
+	Duration   time.Duration
+
+	// Truncated is set for MatchAll calls capped by Config.MaxResults.
+	Truncated bool
+
+	// Err is set when a context-aware call was abandoned, or a match was
+	// ambiguous under Config.StrictTies.
+	Err error
+}
+
+// Hook observes matching, e.g. to record metrics or trace spans. ctx is the
+// context passed to the context-aware variants, or context.Background().
+// OnMatch runs synchronously after the Mux lock is released.
+type Hook interface {
+	OnMatch(ctx context.Context, info MatchInfo)
+}
+
+type HookFunc func(ctx context.Context, info MatchInfo)
+
+func (f HookFunc) OnMatch(ctx context.Context, info MatchInfo) {
+	f(ctx, info)
+}
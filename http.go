@@ -0,0 +1,141 @@
+package mux
+
+import (
+	"context"
+	"net/http"
+)
+
+// HTTPHandler serves requests with the http.Handler values of a Mux, see
+// Mux.MatchHandler.
+type HTTPHandler struct {
+	Mux *Mux
+
+	// Input returns the string r is matched with, r.URL.Path when nil.
+	Input func(r *http.Request) string
+
+	// NotFound serves requests without a matching handler, http.NotFound
+	// when nil.
+	NotFound http.Handler
+
+	// RouteHeader, when set, names a response header set to the matched
+	// pattern.
+	RouteHeader string
+}
+
+// Handler returns an HTTPHandler serving requests with the handlers of m
+// matched on their path.
+func Handler(m *Mux) *HTTPHandler {
+	return &HTTPHandler{Mux: m}
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	input := r.URL.Path
+	if h.Input != nil {
+		input = h.Input(r)
+	}
+	res, err := h.Mux.MatchResultContext(r.Context(), input)
+	handler, ok := asHandler(res.Value)
+	if err != nil || !ok {
+		h.notFound(w, r)
+		return
+	}
+
+	rt, ok := r.Context().Value(routeKey{}).(*matchedRoute)
+	if !ok {
+		rt = new(matchedRoute)
+		r = r.WithContext(context.WithValue(r.Context(), routeKey{}, rt))
+	}
+	rt.pattern, rt.matched = res.Pattern, true
+	rt.tags = h.Mux.tagsOf(res.Pattern)
+	if h.RouteHeader != "" {
+		w.Header().Set(h.RouteHeader, res.Pattern)
+	}
+
+	if !h.allow(w, res.Pattern) {
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+func (h *HTTPHandler) notFound(w http.ResponseWriter, r *http.Request) {
+	if h.NotFound != nil {
+		h.NotFound.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// walkEntry calls f with the entry mapped under the key pattern, under the
+// read lock of its Mux, then with that of each parent in turn until f
+// returns true.
+func (m *Mux) walkEntry(pattern string, f func(e *entry) bool) {
+	for ; m != nil; m = m.parent {
+		m.mtx.RLock()
+		e, ok := m.m[pattern]
+		done := ok && f(e)
+		m.mtx.RUnlock()
+		if done {
+			return
+		}
+	}
+}
+
+// tagsOf returns the tags of the entry mapped under the key pattern,
+// looking through the parents.
+func (m *Mux) tagsOf(pattern string) (tags []string) {
+	m.walkEntry(pattern, func(e *entry) bool {
+		tags = copyTags(e.tags)
+		return true
+	})
+	return tags
+}
+
+// metaValue returns the metadata under key of the entry mapped under the
+// key pattern, looking through the parents.
+func (m *Mux) metaValue(pattern, key string) (v interface{}, ok bool) {
+	m.walkEntry(pattern, func(e *entry) bool {
+		v, ok = e.meta[key]
+		return ok
+	})
+	return v, ok
+}
+
+// matchedRoute is filled in by an HTTPHandler for its handler and for
+// middleware above it, see RouteFromContext.
+type matchedRoute struct {
+	pattern string
+	matched bool
+	tags    []string
+}
+
+type routeKey struct{}
+
+// RouteFromContext returns the pattern an HTTPHandler matched for the
+// request of ctx, the route template to use in metrics and logs in place of
+// the raw path. Handlers served by an HTTPHandler always see it, and so
+// does middleware wrapping one, such as AccessLog, once it has run.
+func RouteFromContext(ctx context.Context) (pattern string, ok bool) {
+	rt, _ := ctx.Value(routeKey{}).(*matchedRoute)
+	if rt == nil || !rt.matched {
+		return "", false
+	}
+	return rt.pattern, true
+}
+
+// RouteTagsFromContext returns the tags of the route an HTTPHandler
+// matched for the request of ctx, such as "team=payments" or "public", for
+// loggers other than AccessLog.
+func RouteTagsFromContext(ctx context.Context) []string {
+	rt, _ := ctx.Value(routeKey{}).(*matchedRoute)
+	if rt == nil {
+		return nil
+	}
+	return rt.tags
+}
+
+// WithRoute returns a copy of ctx in which an HTTPHandler records the route
+// it matches, for middleware wrapping it to read with RouteFromContext
+// once it has run.
+func WithRoute(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, new(matchedRoute))
+}
@@ -0,0 +1,185 @@
+package mux
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+)
+
+// RPCError is a JSON-RPC error object. Handlers return one to choose the
+// code; other errors become RPCInternalError with their message.
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// MethodMatch matches JSON-RPC method names against exact names and
+// namespace wildcards: "users.*" matches every method below users, and
+// "*" every method. Exact names score highest, then deeper namespaces.
+var MethodMatch = func(pattern, s string, index int) (ok bool, score int) {
+	return namespaceMatch(pattern, s)
+}
+
+// RPCHandler handles the params of a JSON-RPC call, returning its result.
+// The method called is in ctx, see RPCMethodFromContext.
+type RPCHandler func(ctx context.Context, params json.RawMessage) (result interface{}, err error)
+
+type rpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcMethodKey struct{}
+
+// RPCMethodFromContext returns the method of the JSON-RPC call handled
+// with ctx.
+func RPCMethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(rpcMethodKey{}).(string)
+	return method
+}
+
+// RPCRouter dispatches JSON-RPC 2.0 requests, single or batched, to
+// handlers by method name, see MethodMatch.
+type RPCRouter struct {
+	m *Mux
+}
+
+// NewRPCRouter returns an empty RPCRouter.
+func NewRPCRouter() *RPCRouter {
+	return &RPCRouter{m: New(Config{Matcher: MethodMatch})}
+}
+
+// Handle routes the methods of pattern to h.
+func (r *RPCRouter) Handle(pattern string, h RPCHandler) {
+	r.m.Map(pattern, h)
+}
+
+// Serve handles the JSON-RPC request or batch in data, returning the
+// response to send, or nil when there is none because data held only
+// notifications.
+func (r *RPCRouter) Serve(ctx context.Context, data []byte) []byte {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return encodeRPC(rpcFailure(nil, RPCParseError, "parse error"))
+		}
+		if len(batch) == 0 {
+			return encodeRPC(rpcFailure(nil, RPCInvalidRequest, "empty batch"))
+		}
+		var resps []*rpcResponse
+		for _, req := range batch {
+			if resp := r.call(ctx, req); resp != nil {
+				resps = append(resps, resp)
+			}
+		}
+		if resps == nil {
+			return nil
+		}
+		return encodeRPC(resps)
+	}
+
+	if !json.Valid(data) {
+		return encodeRPC(rpcFailure(nil, RPCParseError, "parse error"))
+	}
+	if resp := r.call(ctx, data); resp != nil {
+		return encodeRPC(resp)
+	}
+	return nil
+}
+
+// call handles one request, returning nil for notifications.
+func (r *RPCRouter) call(ctx context.Context, data json.RawMessage) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.Version != "2.0" || req.Method == "" {
+		return rpcFailure(nil, RPCInvalidRequest, "invalid request")
+	}
+	// a missing id is a notification, a null one is not
+	notify := req.ID == nil
+
+	res, err := r.m.MatchResult(req.Method)
+	if err != nil {
+		if notify {
+			return nil
+		}
+		return rpcFailure(req.ID, RPCMethodNotFound, "method not found: "+req.Method)
+	}
+	result, err := res.Value.(RPCHandler)(context.WithValue(ctx, rpcMethodKey{}, req.Method), req.Params)
+	if notify {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: RPCInternalError, Message: err.Error()}
+		}
+		return &rpcResponse{Version: "2.0", Error: rpcErr, ID: req.ID}
+	}
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	return &rpcResponse{Version: "2.0", Result: result, ID: req.ID}
+}
+
+func rpcFailure(id json.RawMessage, code int, msg string) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{Version: "2.0", Error: &RPCError{Code: code, Message: msg}, ID: id}
+}
+
+func encodeRPC(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(rpcFailure(nil, RPCInternalError, err.Error()))
+	}
+	return b
+}
+
+// ServeHTTP serves JSON-RPC over HTTP POST, answering requests of only
+// notifications with 204 No Content.
+func (r *RPCRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := r.Serve(req.Context(), data)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
@@ -0,0 +1,176 @@
+package mux
+
+import (
+	"net/url"
+	"strings"
+)
+
+type labelOp int
+
+const (
+	labelEquals labelOp = iota
+	labelNotEquals
+	labelIn
+	labelNotIn
+	labelExists
+	labelNotExists
+)
+
+type labelRequirement struct {
+	key    string
+	op     labelOp
+	values []string
+}
+
+func (r labelRequirement) matches(labels url.Values) bool {
+	_, has := labels[r.key]
+	v := labels.Get(r.key)
+
+	switch r.op {
+	case labelEquals:
+		return has && v == r.values[0]
+	case labelNotEquals:
+		return !has || v != r.values[0]
+	case labelIn, labelNotIn:
+		in := false
+		for _, want := range r.values {
+			if has && v == want {
+				in = true
+				break
+			}
+		}
+		return in == (r.op == labelIn)
+	case labelExists:
+		return has
+	default:
+		return !has
+	}
+}
+
+// splitSelector splits on commas outside parentheses.
+func splitSelector(s string) (parts []string) {
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseSetValues(s string) ([]string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, false
+	}
+	var values []string
+	for _, v := range strings.Split(s[1:len(s)-1], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values, true
+}
+
+func parseLabelRequirement(s string) (r labelRequirement, ok bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return r, false
+	}
+
+	if s[0] == '!' {
+		r.key, r.op = strings.TrimSpace(s[1:]), labelNotExists
+		return r, r.key != ""
+	}
+	if i := strings.Index(s, "!="); i >= 0 {
+		r.key, r.op = strings.TrimSpace(s[:i]), labelNotEquals
+		r.values = []string{strings.TrimSpace(s[i+2:])}
+		return r, r.key != ""
+	}
+	if i := strings.IndexByte(s, '='); i >= 0 {
+		r.key, r.op = strings.TrimSpace(s[:i]), labelEquals
+		r.values = []string{strings.TrimSpace(strings.TrimPrefix(s[i+1:], "="))}
+		return r, r.key != ""
+	}
+
+	fields := strings.Fields(s)
+	switch {
+	case len(fields) == 1:
+		r.key, r.op = fields[0], labelExists
+		return r, true
+	case len(fields) >= 2 && (fields[1] == "in" || fields[1] == "notin"):
+		r.key, r.op = fields[0], labelIn
+		if fields[1] == "notin" {
+			r.op = labelNotIn
+		}
+		rest := strings.TrimSpace(s[len(fields[0]):])
+		r.values, ok = parseSetValues(rest[len(fields[1]):])
+		return r, ok
+	}
+	return r, false
+}
+
+func parseLabelSelector(pattern string) (reqs []labelRequirement, ok bool) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, true
+	}
+	for _, part := range splitSelector(pattern) {
+		r, ok := parseLabelRequirement(part)
+		if !ok {
+			return nil, false
+		}
+		reqs = append(reqs, r)
+	}
+	return reqs, true
+}
+
+// EncodeLabels encodes a label set into the input form LabelSelectorMatch
+// expects.
+func EncodeLabels(labels map[string]string) string {
+	v := make(url.Values, len(labels))
+	for k, l := range labels {
+		v.Set(k, l)
+	}
+	return v.Encode()
+}
+
+// LabelSelectorMatch matches label sets encoded with EncodeLabels against
+// Kubernetes style selectors such as "env=prod,region in (us,eu),!canary".
+// Supported requirements are "k=v", "k==v", "k!=v", "k in (...)",
+// "k notin (...)", "k" and "!k". A selector scores one point per
+// requirement; the empty selector matches everything with score 0.
+var LabelSelectorMatch = func(pattern, s string, index int) (ok bool, score int) {
+	reqs, ok := parseLabelSelector(pattern)
+	if !ok {
+		return false, 0
+	}
+	labels, err := url.ParseQuery(s)
+	if err != nil {
+		return false, 0
+	}
+	for _, r := range reqs {
+		if !r.matches(labels) {
+			return false, 0
+		}
+	}
+	return true, len(reqs)
+}
+
+func NewLabelMux() *Mux {
+	return New(Config{
+		Matcher: LabelSelectorMatch,
+	})
+}
+
+// MatchLabels returns the value whose selector best matches labels.
+func (m *Mux) MatchLabels(labels map[string]string) (val interface{}) {
+	return m.Match(EncodeLabels(labels))
+}
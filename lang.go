@@ -0,0 +1,48 @@
+package mux
+
+import "strings"
+
+// LangTrim normalizes a language tag for comparison: "zh_Hant_TW" becomes
+// "zh-hant-tw".
+var LangTrim = func(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "_", "-"))
+}
+
+// LangFallback returns the RFC 4647 lookup fallback chain for tag, from the
+// tag itself down to its primary language subtag. "zh-Hant-TW" yields
+// "zh-Hant-TW", "zh-Hant", "zh"; singletons left dangling by truncation are
+// dropped, so "de-DE-x-goethe" yields "de-DE-x-goethe", "de-DE", "de".
+func LangFallback(tag string) (chain []string) {
+	subtags := strings.Split(tag, "-")
+	for n := len(subtags); n > 0; n-- {
+		if n < len(subtags) && len(subtags[n-1]) == 1 {
+			continue
+		}
+		chain = append(chain, strings.Join(subtags[:n], "-"))
+	}
+	return
+}
+
+// LangMatch implements RFC 4647 lookup: a pattern matches a tag that equals
+// it or falls back to it, and patterns closer to the input tag (more
+// subtags) score higher. The pattern "*" matches any tag with the lowest
+// score, acting as the lookup default.
+var LangMatch = func(pattern, s string, index int) (ok bool, score int) {
+	if pattern == "*" {
+		return s != "", 0
+	}
+	for _, tag := range LangFallback(s) {
+		if strings.EqualFold(tag, pattern) {
+			return true, strings.Count(pattern, "-") + 1
+		}
+	}
+	return false, 0
+}
+
+func NewLangMux() *Mux {
+	return New(Config{
+		TrimPattern: LangTrim,
+		TrimString:  LangTrim,
+		Matcher:     LangMatch,
+	})
+}
@@ -0,0 +1,61 @@
+package mux
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+type lazyValue struct {
+	f    func() (interface{}, error)
+	once sync.Once
+	done atomic.Bool
+	val  interface{}
+	err  error
+}
+
+func (l *lazyValue) get() (interface{}, error) {
+	l.once.Do(func() {
+		l.val, l.err = l.f()
+		l.done.Store(true)
+	})
+	return l.val, l.err
+}
+
+// resolved returns the value without running the provider, reporting
+// whether it has run.
+func (l *lazyValue) resolved() (interface{}, bool) {
+	if !l.done.Load() {
+		return nil, false
+	}
+	return l.val, true
+}
+
+// MapLazy maps pattern to the value returned by f, calling f once, the
+// first time the pattern matches, e.g. to defer building expensive
+// handlers for rarely used routes. Concurrent first matches wait for the
+// one call. When f fails, the entry keeps matching with a nil value and the
+// error is logged with Config.Logger. Mapping the pattern again drops f.
+func (m *Mux) MapLazy(pattern string, f func() (interface{}, error)) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, p := range m.expand(pattern) {
+		if e := m.mapEntry(p, nil); e != nil {
+			e.lazy = &lazyValue{f: f}
+		}
+	}
+}
+
+// valueOf returns the value of the entry e mapped under p, resolving it
+// when lazy.
+func (m *Mux) valueOf(p string, e *entry) interface{} {
+	if e.lazy == nil {
+		return e.val
+	}
+	v, err := e.lazy.get()
+	if err != nil && m.logger != nil {
+		m.logger.Error("mux: lazy value failed", slog.String("pattern", p), slog.Any("error", err))
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package mux
+
+const (
+	likeLiteral = iota
+	likeOne
+	likeAny
+)
+
+type likeToken struct {
+	kind int
+	r    rune
+}
+
+func compileLike(pattern string, escape rune) (tokens []likeToken, literals int) {
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+			tokens = append(tokens, likeToken{likeLiteral, r})
+			literals++
+		case escape != 0 && r == escape:
+			escaped = true
+		case r == '%':
+			tokens = append(tokens, likeToken{kind: likeAny})
+		case r == '_':
+			tokens = append(tokens, likeToken{kind: likeOne})
+		default:
+			tokens = append(tokens, likeToken{likeLiteral, r})
+			literals++
+		}
+	}
+	if escaped {
+		// a trailing escape character stands for itself
+		tokens = append(tokens, likeToken{likeLiteral, escape})
+		literals++
+	}
+	return
+}
+
+func matchLike(tokens []likeToken, s []rune) bool {
+	t, i := 0, 0
+	starT, starI := -1, 0
+	for i < len(s) {
+		switch {
+		case t < len(tokens) && tokens[t].kind == likeAny:
+			starT, starI = t, i
+			t++
+		case t < len(tokens) && (tokens[t].kind == likeOne || tokens[t].r == s[i]):
+			t++
+			i++
+		case starT >= 0:
+			starI++
+			t, i = starT+1, starI
+		default:
+			return false
+		}
+	}
+	for t < len(tokens) && tokens[t].kind == likeAny {
+		t++
+	}
+	return t == len(tokens)
+}
+
+// LikeMatchFn returns a matcher with SQL LIKE semantics: "%" matches any
+// sequence of characters, "_" matches exactly one, and escape (0 for none)
+// makes the following character literal. Patterns with more literal
+// characters score higher.
+func LikeMatchFn(escape rune) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, score int) {
+		tokens, literals := compileLike(pattern, escape)
+		return matchLike(tokens, []rune(s)), literals
+	}
+}
+
+// LikeMatch is LikeMatchFn with backslash as the escape character.
+var LikeMatch = LikeMatchFn('\\')
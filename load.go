@@ -0,0 +1,166 @@
+package mux
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RouteSpec is one route in a route file.
+type RouteSpec struct {
+	Pattern  string                 `json:"pattern"`
+	Value    interface{}            `json:"value"`
+	Weight   int                    `json:"weight,omitempty"`
+	Priority int                    `json:"priority,omitempty"`
+	Tags     []string               `json:"tags,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Window   *Window                `json:"window,omitempty"`
+}
+
+// RouteDefaults apply to the routes of a route file leaving the
+// corresponding field unset. Metadata is merged, route keys winning.
+type RouteDefaults struct {
+	Weight   int                    `json:"weight,omitempty"`
+	Priority int                    `json:"priority,omitempty"`
+	Tags     []string               `json:"tags,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RouteFile is the JSON document read by LoadRoutes and LoadMux:
+//
+//	{
+//	  "mux": {"trim": "path", "match": "path", "tieBreak": "first"},
+//	  "defaults": {"tags": ["public"]},
+//	  "routes": [
+//	    {"pattern": "/api/", "value": "api", "priority": 1, "tags": []},
+//	    {"pattern": "/sale/", "value": "sale", "window": {"notAfter": "2026-12-31T00:00:00Z"}},
+//	    {"pattern": "/", "value": "frontend"}
+//	  ]
+//	}
+//
+// Routes are mapped in order, so later routes replace earlier ones with
+// the same pattern. Values keep their JSON types. Mux is only used by
+// LoadMux. Unknown keys are ignored; see ValidateRoutes to catch them.
+type RouteFile struct {
+	Mux      *Spec         `json:"mux,omitempty"`
+	Defaults RouteDefaults `json:"defaults,omitempty"`
+	Routes   []RouteSpec   `json:"routes"`
+}
+
+// LoadRoutes maps the routes of the RouteFile read from r in one locked
+// operation. Nothing is mapped if the document cannot be decoded or has a
+// bad window schedule. ErrFull is returned, with the routes that fit
+// mapped, when a table capped with EvictReject runs out of room.
+func (m *Mux) LoadRoutes(r io.Reader) error {
+	var f RouteFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return fmt.Errorf("mux: decoding routes: %w", err)
+	}
+	return m.mapSpecs(&f)
+}
+
+// LoadRoutesFile is LoadRoutes reading from the named file.
+func (m *Mux) LoadRoutesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := m.LoadRoutes(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadMux returns a Mux configured by the Mux spec of the RouteFile read
+// from r, or a strict Mux when there is none, holding its routes.
+func LoadMux(r io.Reader) (*Mux, error) {
+	var f RouteFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("mux: decoding routes: %w", err)
+	}
+	var c Config
+	if f.Mux != nil {
+		var err error
+		if c, err = f.Mux.Config(); err != nil {
+			return nil, err
+		}
+	}
+	m := New(c)
+	if err := m.mapSpecs(&f); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadMuxFile is LoadMux reading from the named file.
+func LoadMuxFile(path string) (*Mux, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := LoadMux(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (d *RouteDefaults) apply(r RouteSpec) RouteSpec {
+	if r.Weight == 0 {
+		r.Weight = d.Weight
+	}
+	if r.Priority == 0 {
+		r.Priority = d.Priority
+	}
+	if r.Tags == nil {
+		r.Tags = d.Tags
+	}
+	if d.Metadata != nil {
+		meta := copyMeta(d.Metadata)
+		for k, v := range r.Metadata {
+			meta[k] = v
+		}
+		r.Metadata = meta
+	}
+	return r
+}
+
+func (m *Mux) mapSpecs(f *RouteFile) error {
+	windows := make([]*window, len(f.Routes))
+	for i, r := range f.Routes {
+		if r.Window != nil {
+			var err error
+			if windows[i], err = r.Window.compile(); err != nil {
+				return fmt.Errorf("mux: route %q: %w", r.Pattern, err)
+			}
+		}
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var err error
+	for i, r := range f.Routes {
+		r = f.Defaults.apply(r)
+		for _, p := range m.expand(r.Pattern) {
+			e := m.mapEntry(p, r.Value)
+			if e == nil {
+				err = ErrFull
+				continue
+			}
+			e.priority = r.Priority
+			e.weight = r.Weight
+			if e.weight == 0 {
+				e.weight = 1
+			}
+			e.meta, e.tags = copyMeta(r.Metadata), copyTags(r.Tags)
+			e.window = windows[i]
+		}
+	}
+	return err
+}
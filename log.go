@@ -0,0 +1,75 @@
+package mux
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+func (m *Mux) debug(ctx context.Context) bool {
+	return m.logger != nil && m.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+func (m *Mux) logMapped(key string, e *entry, added bool) {
+	if !m.debug(context.Background()) {
+		return
+	}
+	msg := "mux: remapped"
+	if added {
+		msg = "mux: mapped"
+	}
+	m.logger.Debug(msg, "pattern", key, "index", e.index)
+}
+
+func (m *Mux) logDeleted(key string, e *entry) {
+	if m.debug(context.Background()) {
+		m.logger.Debug("mux: deleted", "pattern", key, "index", e.index)
+	}
+}
+
+func (m *Mux) logCleared(n int) {
+	if n > 0 && m.debug(context.Background()) {
+		m.logger.Debug("mux: cleared", "entries", n)
+	}
+}
+
+func (m *Mux) logFull(key string) {
+	if m.logger != nil {
+		m.logger.Warn("mux: too many entries", "pattern", key, "max", m.maxEntries)
+	}
+}
+
+// logMatch logs the outcome of a Match call, listing the other matching
+// entries seen as "pattern=score".
+func (m *Mux) logMatch(ctx context.Context, s string, r MatchResult, found bool, seen []MatchResult, err error) {
+	attrs := []slog.Attr{slog.String("input", s)}
+	if found {
+		attrs = append(attrs, slog.String("pattern", r.Pattern), slog.Int("score", r.Score))
+	}
+	var losers []string
+	for _, c := range seen {
+		if !found || c.Pattern != r.Pattern {
+			losers = append(losers, fmt.Sprintf("%s=%d", c.Pattern, c.Score))
+		}
+	}
+	if len(losers) > 0 {
+		attrs = append(attrs, slog.Any("losers", losers))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	msg := "mux: no match"
+	if found {
+		msg = "mux: match"
+	}
+	m.logger.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
+}
+
+func (m *Mux) logMatchAll(ctx context.Context, s string, results []MatchResult, err error) {
+	attrs := []slog.Attr{slog.String("input", s), slog.Int("results", len(results))}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	m.logger.LogAttrs(ctx, slog.LevelDebug, "mux: match all", attrs...)
+}
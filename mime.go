@@ -0,0 +1,62 @@
+package mux
+
+import "strings"
+
+// MIMETrim strips media type parameters and lowercases the rest, so
+// "Text/HTML; charset=utf-8" becomes "text/html".
+var MIMETrim = func(s string) string {
+	if i := strings.IndexByte(s, ';'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func splitMIME(s string) (typ, sub string) {
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// MIMEMatch matches media types against patterns such as "image/png",
+// "image/*", "*/*" or "*/*+json". A structured syntax suffix is honoured, so
+// "application/*+json" matches "application/problem+json". Exact types score
+// highest, then suffix wildcards, then subtype wildcards, then "*/*".
+var MIMEMatch = func(pattern, s string, index int) (ok bool, score int) {
+	pt, ps := splitMIME(MIMETrim(pattern))
+	st, ss := splitMIME(MIMETrim(s))
+	if ss == "" {
+		return false, 0
+	}
+
+	if pt != "*" {
+		if pt != st {
+			return false, 0
+		}
+		score += 2
+	}
+
+	switch {
+	case ps == ss:
+		if ps != "*" {
+			score += 4
+		}
+	case ps == "*":
+	case strings.HasPrefix(ps, "*+"):
+		if !strings.HasSuffix(ss, ps[1:]) {
+			return false, 0
+		}
+		score++
+	default:
+		return false, 0
+	}
+	return true, score
+}
+
+func NewMIMEMux() *Mux {
+	return New(Config{
+		TrimPattern: MIMETrim,
+		TrimString:  MIMETrim,
+		Matcher:     MIMEMatch,
+	})
+}
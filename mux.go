@@ -2,127 +2,1209 @@
 package mux
 
 import (
+	"context"
+	"log/slog"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unique"
 )
 
 type entry struct {
-	val   interface{}
-	index int
+	val      interface{}
+	index    int
+	weight   int
+	priority int
+	filter   func(s string) bool
+	matcher  MatchFunc
+	trim     TrimFunc
+	meta     map[string]interface{}
+	tags     []string
+	budget   time.Duration
+	lazy     *lazyValue
+
+	// rollout is the percentage of inputs served when rolled, see
+	// MapWithRollout.
+	rollout float64
+	rolled  bool
+
+	// disabled entries are skipped by matching, see Disable, and so are
+	// entries outside their window.
+	disabled bool
+	window   *window
+
+	// adjust is added to the entry's scores, see AdjustScore.
+	adjust int
+
+	// original is the pattern as registered, when trimming changed it.
+	original string
+
+	// unhealthy entries are skipped too, see SetHealthy. It is set without
+	// the write lock.
+	unhealthy atomic.Bool
+
+	// hits and lastHit are kept under Config.TrackStats, along with the
+	// time the entry was created.
+	hits    atomic.Uint64
+	lastHit atomic.Int64
+	created int64
 }
 
 type Config struct {
 	TrimPattern TrimFunc
 	TrimString  TrimFunc
 	Matcher     MatchFunc
+
+	// TrimStringErr, when set, runs before TrimString and can reject
+	// inputs, e.g. with UTF8Check or PathUnescapeTrim, so malformed ones
+	// match nothing instead of being guessed at. MatchResult, MatchAllContext
+	// and the context variants report them with a *TrimError.
+	TrimStringErr TrimErrFunc
+
+	// ContextMatcher, when set, is used instead of Matcher and is given
+	// the context passed to MatchContext and the other context variants,
+	// or context.Background() by the rest.
+	ContextMatcher ContextMatchFunc
+
+	// ExpandBraces makes the Map methods and Delete apply ExpandBraces to
+	// their pattern, registering or removing every alternative.
+	ExpandBraces bool
+
+	// Vars, when set, makes the Map methods and Delete expand "${NAME}"
+	// placeholders in their pattern with ExpandVars, before any brace
+	// expansion.
+	Vars VarFunc
+
+	// CompareScores orders scores, returning a positive number when a ranks
+	// above b, a negative one when it ranks below and 0 on a tie. When nil,
+	// higher scores rank above lower ones. See Float64Score for matchers
+	// whose natural score is a float64.
+	CompareScores func(a, b int) int
+
+	// ScoreBound, when set, returns the highest score the matcher can give
+	// pattern for any input. Entries are then scanned best bound first and
+	// matching stops once no remaining entry can win. See PatternLengthBound.
+	ScoreBound func(pattern string, index int) int
+
+	// Prefilter, when set, derives a cheap test from each pattern as it is
+	// mapped. Inputs failing the test skip the matcher for that pattern. See
+	// RegexPrefilter and LikePrefilter.
+	Prefilter PrefilterFunc
+
+	// InternPatterns stores patterns as canonical interned strings, shared
+	// with every other Mux doing the same, so large tables of repeated or
+	// generated patterns hold one copy of each and patterns returned from
+	// matching compare by pointer.
+	InternPatterns bool
+
+	// Reconfig decides what SetMatcher, SetStringTrimmer and
+	// SetPatternTrimmer do once entries exist. The default, ReconfigAllow,
+	// applies the change as is.
+	Reconfig ReconfigPolicy
+
+	// Codec encodes values and metadata for Save and Load. GobCodec is used
+	// when nil.
+	Codec ValueCodec
+
+	// Hook, when set, is told about every Match and MatchAll call.
+	Hook Hook
+
+	// RecoverPanics makes matching recover from panics in trimmers,
+	// prefilters and matchers. The entry being matched is skipped, or the
+	// whole input when the string trimmer panics, and a PanicError is passed
+	// to OnPanic, or logged with Logger at slog.LevelError when OnPanic is
+	// nil.
+	RecoverPanics bool
+	OnPanic       func(err *PanicError)
+
+	// MatchBudget, when positive, bounds the time one entry may take to
+	// match an input. Entries over budget are skipped and reported to
+	// OnBudgetExceeded, or logged with Logger at slog.LevelWarn when it is
+	// nil. Each budgeted match runs in its own goroutine, which is left to
+	// finish in the background when over budget; see MapWithBudget to budget
+	// only the slow entries. A panic within the budget is raised again in
+	// the goroutine calling Match, unless RecoverPanics is set, while one in
+	// a match left behind can only be passed to OnPanic or logged.
+	MatchBudget      time.Duration
+	OnBudgetExceeded func(pattern, s string, budget time.Duration)
+
+	// Logger, when set, logs registrations, deletions and match summaries,
+	// including the scores of losing candidates, at slog.LevelDebug.
+	Logger *slog.Logger
+
+	// MinScore, when non-zero, makes matches scoring below it, as ordered by
+	// CompareScores, count as non-matches. The MinScore option overrides it
+	// for a single call.
+	MinScore int
+
+	// MaxResults, when positive, caps the number of results a MatchAll call
+	// collects. Further matches are dropped unseen, so which ones are kept
+	// is arbitrary, before any Offset and Limit. MatchAllContext reports
+	// capped calls with ErrTruncated, and hooks with MatchInfo.Truncated.
+	// MatchAllFunc is not capped.
+	MaxResults int
+
+	// Fallback, when set, makes Match retry inputs that match nothing with
+	// their fallback, and so on until one matches or Fallback reports
+	// false. See PathParent. MatchAll does not fall back.
+	Fallback FallbackFunc
+
+	// OnEvict, when set, is called with the pattern and value of entries
+	// deleted, cleared or replaced, and with the old value of entries mapped
+	// to a different value, so values holding resources can release them.
+	// Lazy values are only reported once resolved. It runs under the write
+	// lock and must not use the Mux.
+	OnEvict func(pattern string, val interface{})
+
+	// StrictTies makes Match fail instead of picking a winner when several
+	// entries share the best match: MatchResult and the context variants
+	// return a *MatchError wrapping ErrAmbiguous listing them, and the other
+	// Match methods return no match. TieBreak is then not used.
+	StrictTies bool
+
+	// TrackStats makes the Mux count the matches of every entry and record
+	// when it last matched, see Stats. Match counts the winner only, and
+	// MatchAll every result.
+	TrackStats bool
+
+	// MaxEntries, when positive, caps the number of entries, aliases aside.
+	// Mapping a new pattern into a full table first evicts an entry chosen
+	// by Eviction, or with EvictReject drops the new pattern, making TryMap
+	// fail with ErrFull. EvictLRU and EvictLFU imply TrackStats.
+	MaxEntries int
+	Eviction   EvictionPolicy
+
+	// OrderedResults makes MatchAll always return results ranked the way
+	// Match ranks them, by priority, then score, then insertion index, as
+	// paged calls do, e.g. for rule engines where rule order matters.
+	// MatchAllFunc still visits matches in no particular order.
+	OrderedResults bool
+
+	// RecentMatches, when positive, keeps that many of the latest Match
+	// calls for RecentMatches to return.
+	RecentMatches int
+
+	// Name names the Mux in pprof labels.
+	Name string
+
+	// ProfileLabels makes matching run under pprof labels, "mux" set to
+	// Name and "mux.pattern" to the pattern being matched, so CPU profiles
+	// attribute time to tables and routes. Labels are added to those of the
+	// context passed to the context variants, while the other Match methods
+	// clear the caller's goroutine labels. MatchAllFunc is not labelled.
+	// Labelling costs a few allocations per entry matched.
+	ProfileLabels bool
+
+	// TieBreak picks the winner when several entries share the best score,
+	// e.g. FirstRegistered, LastRegistered, LongestPattern or a custom
+	// TieBreakFunc. When nil, an arbitrary one of them wins.
+	TieBreak TieBreakFunc
+
+	// History is the number of versions Checkpoint keeps for Rollback, the
+	// oldest being dropped first. DefaultHistory are kept when zero.
+	History int
 }
 
 type Mux struct {
-	trimPattern TrimFunc
-	trimString  TrimFunc
-	matcher     MatchFunc
+	trimPattern   TrimFunc
+	trimString    TrimFunc
+	trimStringErr TrimErrFunc
+	ctxMatcher    ContextMatchFunc
+	matcher       MatchFunc
+	tieBreak      TieBreakFunc
+	compare       func(a, b int) int
+	scoreBound    func(pattern string, index int) int
+	minScore      int
+	maxResults    int
+	fallback      FallbackFunc
+	onEvict       func(pattern string, val interface{})
+	strict        bool
+	stats         bool
+	maxEntries    int
+	eviction      EvictionPolicy
+	sortAll       bool
+	recent        *recentRing
+	name          string
+	profile       bool
+	prefilter     PrefilterFunc
+	braces        bool
+	vars          VarFunc
+	intern        bool
+	reconfig      ReconfigPolicy
+	codec         ValueCodec
+	hook          Hook
+	logger        *slog.Logger
+	recoverPanics bool
+	onPanic       func(err *PanicError)
+	budget        time.Duration
+	onBudget      func(pattern, s string, budget time.Duration)
+	history       int
 
 	m     map[string]*entry
 	mtx   sync.RWMutex
 	index int
+
+	// ownTrims counts entries with their own pattern trimmer, and
+	// ownMatchers those with their own matcher.
+	ownTrims    int
+	ownMatchers int
+
+	// exact is set when a Match can look its input up directly, see
+	// updateExact.
+	exact bool
+
+	// prefixKeys is set when only entries keyed by a prefix of the input
+	// can match it, see updateExact.
+	prefixKeys bool
+
+	// parent is matched when the Mux itself has no match, see NewChild.
+	parent *Mux
+
+	// aliases maps the keys registered with Alias to their canonical key,
+	// and aliasOriginals those trimming changed to their spelling.
+	aliases        map[string]string
+	aliasOriginals map[string]string
+
+	// versions are the checkpoints kept for Rollback, oldest first, and
+	// version the number of the last one.
+	versions []checkpoint
+	version  int
+
+	// standby is the table switched out by the last Promote or Revert.
+	standby *table
+
+	// shadow holds the entries of MapShadow.
+	shadow *Mux
+
+	order    []bounded
+	orderMtx sync.Mutex
+
+	// allHint is the size of the last MatchAll result, used to preallocate
+	// the next one.
+	allHint atomic.Int64
+}
+
+type MatchResult struct {
+	Pattern string
+	Value   interface{}
+	Score   int
+
+	// Index is the entry's insertion index. It increases with every new
+	// pattern and is kept when a pattern is mapped again.
+	Index int
+
+	Priority int
+
+	// Original is Pattern as registered, before trimming.
+	Original string
+}
+
+// SetPatternTrimmer replaces the pattern trimmer. Under ReconfigRetrim the
+// keys of existing entries are trimmed again with f.
+func (m *Mux) SetPatternTrimmer(f TrimFunc) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := m.reconfigure(); err != nil {
+		return err
+	}
+	m.trimPattern = f
+	if m.reconfig == ReconfigRetrim {
+		m.retrim()
+	}
+	return nil
 }
 
-func (m *Mux) SetStringTrimmer(f TrimFunc) {
+func (m *Mux) SetStringTrimmer(f TrimFunc) error {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
+	if err := m.reconfigure(); err != nil {
+		return err
+	}
 	m.trimString = f
+	m.updateExact()
+	return nil
 }
 
-func (m *Mux) SetMatcher(f MatchFunc) {
+// SetMatcher replaces the matcher. Any score bound belonged to the old
+// matcher and is removed; use SetScoreBound to install a new one.
+func (m *Mux) SetMatcher(f MatchFunc) error {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
+	if err := m.reconfigure(); err != nil {
+		return err
+	}
 	m.matcher = f
+	m.scoreBound = nil
+	m.order = nil
+	m.setPrefilter(nil)
+	m.updateExact()
+	return nil
+}
+
+// SetPrefilter replaces the prefilter and rebuilds the tests of existing
+// entries.
+func (m *Mux) SetPrefilter(f PrefilterFunc) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.setPrefilter(f)
+}
+
+func (m *Mux) setPrefilter(f PrefilterFunc) {
+	m.prefilter = f
+	for p, e := range m.m {
+		e.filter = nil
+		if f != nil && e.matcher == nil {
+			e.filter = f(p)
+		}
+	}
+}
+
+func (m *Mux) SetScoreBound(f func(pattern string, index int) int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.scoreBound = f
+	m.order = nil
+}
+
+func (m *Mux) SetTieBreak(f TieBreakFunc) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.tieBreak = f
 }
 
 func (m *Mux) Map(pattern string, val interface{}) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
-	pattern = m.trimPattern(pattern)
+	for _, p := range m.expand(pattern) {
+		m.mapEntry(p, val)
+	}
+}
 
-	if e, ok := m.m[pattern]; ok {
-		e.val = val
+// MapAll maps every pattern to val in one locked operation, so readers see
+// either none or all of them.
+func (m *Mux) MapAll(patterns []string, val interface{}) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, pattern := range patterns {
+		for _, p := range m.expand(pattern) {
+			m.mapEntry(p, val)
+		}
+	}
+}
+
+// MapWeighted maps pattern to val with the given weight, which tie-break
+// policies such as WeightedRandomTieBreak use. Map uses a weight of 1.
+func (m *Mux) MapWeighted(pattern string, val interface{}, weight int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, p := range m.expand(pattern) {
+		if e := m.mapEntry(p, val); e != nil {
+			e.weight = weight
+		}
+	}
+}
+
+// MapWithPriority maps pattern to val with the given priority. Priority is
+// compared before the matcher score: any matching entry with a higher
+// priority beats every entry with a lower one, and the score only ranks
+// entries of equal priority. Map uses a priority of 0.
+func (m *Mux) MapWithPriority(pattern string, val interface{}, priority int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, p := range m.expand(pattern) {
+		if e := m.mapEntry(p, val); e != nil {
+			e.priority = priority
+		}
+	}
+}
+
+// MapWithMatcher maps pattern to val and matches it with f instead of the
+// Mux matcher, e.g. to keep a few regexp routes in a prefix table. f's
+// scores are ranked together with those of the Mux matcher. The prefilter
+// and score bound, which describe the Mux matcher, do not apply to the
+// entry. A nil f restores the Mux matcher.
+func (m *Mux) MapWithMatcher(pattern string, val interface{}, f MatchFunc) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, p := range m.expand(pattern) {
+		e := m.mapEntry(p, val)
+		if e == nil {
+			continue
+		}
+		m.setEntryMatcher(e, f)
+		e.filter = nil
+		if f == nil && m.prefilter != nil {
+			e.filter = m.prefilter(m.trimPattern(p))
+		}
+	}
+}
+
+// MapWithTrimmer maps pattern to val, trimming both the pattern and inputs
+// matched against it with f instead of the Mux trimmers, e.g. to keep one
+// case-sensitive route in a case-insensitive table. f receives inputs as
+// passed to Match.
+func (m *Mux) MapWithTrimmer(pattern string, val interface{}, f TrimFunc) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, p := range m.expand(pattern) {
+		key := f(p)
+		if _, alias := m.aliases[key]; alias {
+			// aliased entries cannot have their own trimmer
+			m.remove(key, m.m[key])
+		}
+		e := m.insert(key)
+		if e == nil {
+			continue
+		}
+		if e.trim == nil {
+			m.ownTrims++
+		}
+		m.setValue(key, e, val)
+		e.trim = f
+		e.original = ""
+		if p != key {
+			e.original = p
+		}
+	}
+}
+
+// AdjustScore adds delta to the scores the entry registered under pattern
+// is given from now on, to bias selection towards or away from it at run
+// time, e.g. to shift traffic gradually between tied canaries, without
+// registering it again. Adjustments accumulate. The delta is added to the
+// score as the matcher encodes it, so it raises the entry only when
+// CompareScores ranks higher scores above. It reports whether there is
+// such an entry.
+func (m *Mux) AdjustScore(pattern string, delta int) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	_, e, ok := m.lookup(pattern)
+	if ok {
+		e.adjust += delta
+		m.order = nil
+	}
+	return ok
+}
+
+// SetPriority changes the priority of the entry registered under pattern,
+// reporting whether there is one.
+func (m *Mux) SetPriority(pattern string, priority int) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	_, e, ok := m.lookup(pattern)
+	if ok {
+		e.priority = priority
+		m.order = nil
+	}
+	return ok
+}
+
+// expand applies variable and brace expansion to pattern, in that order.
+// Variables that cannot be expanded are kept verbatim; TryMap reports them.
+func (m *Mux) expand(pattern string) []string {
+	if m.vars != nil {
+		pattern, _ = ExpandVars(pattern, m.vars)
+	}
+	if !m.braces {
+		return []string{pattern}
+	}
+	return ExpandBraces(pattern)
+}
+
+// mapEntry maps pattern to val, returning the entry for further settings,
+// or nil when a table full under EvictReject has no room for it.
+func (m *Mux) mapEntry(pattern string, val interface{}) *entry {
+	key, e, ok := m.lookup(pattern)
+	if !ok {
+		if e = m.insert(key); e == nil {
+			return nil
+		}
 	} else {
-		m.index++
-		m.m[pattern] = &entry{
-			val:   val,
-			index: m.index,
+		m.order = nil
+		m.logMapped(key, e, false)
+	}
+	m.setValue(key, e, val)
+	e.original = ""
+	if pattern != key {
+		e.original = pattern
+	}
+	return e
+}
+
+// lookup finds the entry pattern was mapped to, whether under the Mux
+// pattern trimmer or its own.
+func (m *Mux) lookup(pattern string) (key string, e *entry, ok bool) {
+	key = m.trimPattern(pattern)
+	if e, ok = m.m[key]; ok || m.ownTrims == 0 {
+		return
+	}
+	for k, e := range m.m {
+		if e.trim != nil && e.trim(pattern) == k {
+			return k, e, true
 		}
 	}
+	return key, nil, false
+}
+
+// insert returns the entry under the trimmed pattern key, creating it if
+// needed, or nil when there is no room for it.
+func (m *Mux) insert(pattern string) *entry {
+	m.order = nil
+
+	if e, ok := m.m[pattern]; ok {
+		m.logMapped(pattern, e, false)
+		return e
+	}
+	m.index++
+	e := m.add(pattern, m.index)
+	if e == nil {
+		m.index--
+		return nil
+	}
+	m.logMapped(pattern, e, true)
+	return e
+}
+
+// add stores a new entry with the given index under the trimmed pattern
+// key, returning nil when the table is full under EvictReject.
+func (m *Mux) add(pattern string, index int) *entry {
+	if m.full() && !m.makeRoom() {
+		m.logFull(pattern)
+		return nil
+	}
+	e := &entry{
+		index:  index,
+		weight: 1,
+	}
+	if m.stats {
+		e.created = time.Now().UnixNano()
+	}
+	if m.prefilter != nil {
+		e.filter = m.prefilter(pattern)
+	}
+	if m.intern {
+		pattern = Intern(pattern)
+	}
+	m.m[pattern] = e
+	return e
+}
+
+// Intern returns the canonical copy of s, allocating it on first use.
+func Intern(s string) string {
+	return unique.Make(s).Value()
 }
 
 func (m *Mux) Delete(pattern string) {
+	m.DeleteAll([]string{pattern})
+}
+
+// DeleteAll deletes every pattern in one locked operation, e.g. to
+// unregister a group mapped with MapAll.
+func (m *Mux) DeleteAll(patterns []string) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
-	delete(m.m, m.trimPattern(pattern))
+	for _, pattern := range patterns {
+		for _, p := range m.expand(pattern) {
+			if key, e, ok := m.lookup(p); ok {
+				m.remove(key, e)
+			}
+		}
+	}
+	m.order = nil
+}
+
+func (m *Mux) remove(key string, e *entry) {
+	delete(m.m, key)
+	if _, alias := m.aliases[key]; !alias {
+		// an alias shares the entry, which its canonical pattern counts
+		if e.trim != nil {
+			m.ownTrims--
+		}
+		if e.matcher != nil {
+			m.ownMatchers--
+		}
+		m.evict(key, e)
+	}
+	if len(m.aliases) > 0 {
+		m.removeAliases(key)
+	}
+	m.logDeleted(key, e)
 }
 
 func (m *Mux) Clear() {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
+	m.clear()
+}
+
+func (m *Mux) clear() {
+	m.logCleared(len(m.m))
+	if m.onEvict != nil {
+		for p, e := range m.m {
+			if _, alias := m.aliases[p]; !alias {
+				m.evict(p, e)
+			}
+		}
+	}
 	m.m = make(map[string]*entry)
+	m.ownTrims = 0
+	m.ownMatchers = 0
+	m.aliases, m.aliasOriginals = nil, nil
+	m.order = nil
+}
+
+// Patterns returns the registered patterns ordered by insertion index, as
+// trimmed; see OriginalPatterns for their spelling.
+func (m *Mux) Patterns() []string {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	patterns := make([]string, 0, len(m.m))
+	for p := range m.m {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool { return m.m[patterns[i]].index < m.m[patterns[j]].index })
+	return patterns
+}
+
+// OriginalPatterns returns the registered patterns as they were spelled
+// when registered, before trimming, in the order of Patterns.
+func (m *Mux) OriginalPatterns() []string {
+	patterns := m.Patterns()
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	for i, p := range patterns {
+		if e, ok := m.m[p]; ok {
+			patterns[i] = m.originalOf(p, e)
+		}
+	}
+	return patterns
+}
+
+// originalOf returns the pattern e was registered with under key p.
+// Aliases share the entry of their canonical pattern but keep their key.
+func (m *Mux) originalOf(p string, e *entry) string {
+	if len(m.aliases) > 0 {
+		if _, alias := m.aliases[p]; alias {
+			if orig, ok := m.aliasOriginals[p]; ok {
+				return orig
+			}
+			return p
+		}
+	}
+	if e.original == "" {
+		return p
+	}
+	return e.original
 }
 
-func (m *Mux) Match(s string) (val interface{}) {
-	val, _, _ = m.MatchWithPatternScore(s)
+func (m *Mux) Match(s string, opts ...MatchOption) (val interface{}) {
+	val, _, _ = m.MatchWithPatternScore(s, opts...)
 	return
 }
 
-func (m *Mux) MatchWithPattern(s string) (val interface{}, pattern string) {
-	val, pattern, _ = m.MatchWithPatternScore(s)
+func (m *Mux) MatchWithPattern(s string, opts ...MatchOption) (val interface{}, pattern string) {
+	val, pattern, _ = m.MatchWithPatternScore(s, opts...)
+	return
+}
+
+func (m *Mux) MatchWithPatternScore(s string, opts ...MatchOption) (val interface{}, pattern string, maxScore int) {
+	r, _, _ := m.matchBest(context.Background(), s, opts)
+	return r.Value, r.Pattern, r.Score
+}
+
+// MatchResult returns the best match for s, or a *MatchError wrapping
+// ErrNoMatch when there is none.
+func (m *Mux) MatchResult(s string, opts ...MatchOption) (MatchResult, error) {
+	return m.MatchResultContext(context.Background(), s, opts...)
+}
+
+// matchBest finds the best match for s and reports it to the hook, logger
+// and recent match buffer.
+func (m *Mux) matchBest(ctx context.Context, s string, opts []MatchOption) (r MatchResult, found bool, err error) {
+	if len(opts) == 0 && m.parent == nil {
+		if r, found, ok := m.matchExact(s); ok {
+			return r, found, nil
+		}
+	}
+
+	o := newMatchOptions(opts)
+	if m.hook == nil && m.logger == nil && m.recent == nil {
+		return m.scanBest(ctx, s, &o, nil)
+	}
+
+	var seen *[]MatchResult
+	if m.debug(ctx) {
+		seen = new([]MatchResult)
+	}
+	start := time.Now()
+	r, found, err = m.scanBest(ctx, s, &o, seen)
+	d := time.Since(start)
+	if seen != nil {
+		m.logMatch(ctx, s, r, found, *seen, err)
+	}
+	if m.recent != nil {
+		m.recent.add(RecentMatch{
+			Time:     start,
+			Input:    s,
+			Pattern:  r.Pattern,
+			Score:    r.Score,
+			Matched:  found,
+			Duration: d,
+		})
+	}
+	if m.hook == nil {
+		return
+	}
+	info := MatchInfo{
+		Input:    s,
+		Pattern:  r.Pattern,
+		Score:    r.Score,
+		Matched:  found,
+		Duration: d,
+		Err:      err,
+	}
+	if found {
+		info.Candidates = 1
+	}
+	m.hook.OnMatch(ctx, info)
 	return
 }
 
-func (m *Mux) MatchWithPatternScore(s string) (val interface{}, pattern string, maxScore int) {
+// scanBest scans the entries for the best match for s, retrying with the
+// fallbacks of s until one matches, then with the parent.
+func (m *Mux) scanBest(ctx context.Context, s string, o *matchOptions, seen *[]MatchResult) (r MatchResult, found bool, err error) {
+	if m.profile && !m.labelled(ctx) {
+		return m.scanBestLabelled(ctx, s, o, seen)
+	}
+
 	m.mtx.RLock()
 	defer m.mtx.RUnlock()
 
-	hasOK := false
-	s = m.trimString(s)
-	for p, e := range m.m {
-		if ok, score := m.matcher(p, s, e.index); ok && (!hasOK || score > maxScore) {
-			hasOK, maxScore = true, score
-			val, pattern = e.val, p
+	raw := s
+	for {
+		r, found, err = m.best(ctx, s, o, seen)
+		if found || err != nil || m.fallback == nil {
+			break
+		}
+		var ok bool
+		if s, ok = m.fallback(s); !ok {
+			break
 		}
 	}
-	return
+	if found || err != nil || m.parent == nil {
+		return
+	}
+	return m.parent.scanBest(ctx, raw, o, seen)
+}
+
+// best scans the entries for the best match for s, appending every matching
+// entry it visits to seen when not nil. A cancellable ctx is checked
+// between entries, and the scan is abandoned with ctx.Err() once it is
+// done.
+func (m *Mux) best(ctx context.Context, s string, o *matchOptions, seen *[]MatchResult) (r MatchResult, found bool, err error) {
+	done := ctx.Done()
+	cancelled := func() bool {
+		if done == nil {
+			return false
+		}
+		select {
+		case <-done:
+			err = ctx.Err()
+			return true
+		default:
+			return false
+		}
+	}
+
+	raw := s
+	s, ok, err := m.trimInputErr(s)
+	if !ok {
+		return MatchResult{}, false, err
+	}
+	var best *entry
+	var tied []Candidate
+	ties := m.tieBreak != nil || m.strict
+	visit := func(p string, e *entry) {
+		ok, score := m.matchIn(ctx, p, e, raw, s)
+		if !ok || o.below(m, score) {
+			return
+		}
+		if seen != nil {
+			*seen = append(*seen, MatchResult{Pattern: p, Value: e.val, Score: score, Index: e.index, Priority: e.priority, Original: m.originalOf(p, e)})
+		}
+		c := 1
+		if best != nil {
+			c = m.rank(e, score, best, r.Score)
+		}
+		if c < 0 || (c == 0 && !ties) {
+			return
+		}
+		if c > 0 {
+			best = e
+			r = MatchResult{
+				Pattern:  p,
+				Value:    e.val,
+				Score:    score,
+				Index:    e.index,
+				Priority: e.priority,
+				Original: m.originalOf(p, e),
+			}
+			tied = tied[:0]
+		}
+		if ties {
+			tied = append(tied, Candidate{
+				Pattern:  p,
+				Value:    e.val,
+				Index:    e.index,
+				Weight:   e.weight,
+				Priority: e.priority,
+				Score:    score,
+			})
+		}
+	}
+
+	switch {
+	case m.prefixKeys && m.ownMatchers == 0 && m.ownTrims == 0:
+		for n := len(s); n > 0; n-- {
+			if e, ok := m.m[s[:n]]; ok {
+				visit(s[:n], e)
+			}
+		}
+	case m.scoreBound == nil:
+		for p, e := range m.m {
+			if cancelled() {
+				return MatchResult{}, false, err
+			}
+			visit(p, e)
+		}
+	default:
+		for _, o := range m.ordered() {
+			if cancelled() {
+				return MatchResult{}, false, err
+			}
+			if best != nil && !o.unbounded {
+				// no remaining entry can beat, or with a tie-break tie, the best
+				if c := m.rank(o.e, o.bound, best, r.Score); c < 0 || (c == 0 && !ties) {
+					break
+				}
+			}
+			visit(o.pattern, o.e)
+		}
+	}
+
+	if len(tied) > 1 && m.strict {
+		patterns := make([]string, len(tied))
+		for i, c := range tied {
+			patterns[i] = c.Pattern
+		}
+		sort.Strings(patterns)
+		return MatchResult{}, false, &MatchError{Input: raw, Patterns: patterns, Err: ErrAmbiguous}
+	}
+	if len(tied) > 1 {
+		sort.Slice(tied, func(i, j int) bool { return tied[i].Index < tied[j].Index })
+		key := s
+		if o.hasAffinity {
+			key = o.affinity
+		}
+		c := tied[m.tieBreak(key, tied)]
+		r.Pattern, r.Value, r.Index = c.Pattern, c.Value, c.Index
+		best = m.m[c.Pattern]
+		r.Original = m.originalOf(c.Pattern, best)
+	}
+	if best != nil {
+		m.hit(best)
+		if best.lazy != nil {
+			r.Value = m.valueOf(r.Pattern, best)
+		}
+	}
+	return r, best != nil, nil
+}
+
+// match matches input s, raw before trimming, against the entry e mapped
+// under p.
+func (m *Mux) match(ctx context.Context, p string, e *entry, raw, s string) (ok bool, score int) {
+	if e.disabled || e.unhealthy.Load() || (e.rolled && !inRollout(s, e.rollout)) || (e.window != nil && !e.window.active(time.Now())) {
+		return false, 0
+	}
+	if ok, score = m.matchScore(ctx, p, e, raw, s); ok {
+		score += e.adjust
+	}
+	return ok, score
+}
+
+func (m *Mux) matchScore(ctx context.Context, p string, e *entry, raw, s string) (ok bool, score int) {
+	if d := m.budgetOf(e); d > 0 {
+		return m.matchWithin(ctx, d, p, e, raw, s)
+	}
+	if m.recoverPanics {
+		defer m.recoverPanic(p, raw)
+	}
+	return matchEntry(p, e.index, e.trim, m.filterOf(p, e), m.matcherOf(ctx, e), raw, s)
+}
+
+func (m *Mux) matcherOf(ctx context.Context, e *entry) MatchFunc {
+	switch {
+	case e.matcher != nil:
+		return e.matcher
+	case m.ctxMatcher != nil:
+		return func(pattern, s string, index int) (ok bool, score int) {
+			return m.ctxMatcher(ctx, pattern, s, index)
+		}
+	}
+	return m.matcher
+}
+
+func matchEntry(p string, index int, trim TrimFunc, filter func(string) bool, f MatchFunc, raw, s string) (ok bool, score int) {
+	if trim != nil {
+		s = trim(raw)
+	}
+	if filter != nil && !filter(s) {
+		return false, 0
+	}
+	return f(p, s, index)
+}
+
+// rank orders entry a with score sa against entry b with score sb.
+func (m *Mux) rank(a *entry, sa int, b *entry, sb int) int {
+	switch {
+	case a.priority > b.priority:
+		return 1
+	case a.priority < b.priority:
+		return -1
+	}
+	return m.compareScores(sa, sb)
+}
+
+func (m *Mux) compareScores(a, b int) int {
+	if m.compare != nil {
+		return m.compare(a, b)
+	}
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	}
+	return 0
 }
 
-func (m *Mux) MatchAll(s string) (vals []interface{}) {
-	vals, _, _ = m.MatchAllWithPatternScore(s)
+func (m *Mux) MatchAll(s string, opts ...MatchOption) (vals []interface{}) {
+	vals, _, _ = m.MatchAllWithPatternScore(s, opts...)
 	return
 }
 
-func (m *Mux) MatchAllWithPattern(s string) (vals []interface{}, patterns []string) {
-	vals, patterns, _ = m.MatchAllWithPatternScore(s)
+func (m *Mux) MatchAllWithPattern(s string, opts ...MatchOption) (vals []interface{}, patterns []string) {
+	vals, patterns, _ = m.MatchAllWithPatternScore(s, opts...)
 	return
 }
 
-func (m *Mux) MatchAllWithPatternScore(s string) (vals []interface{}, patterns []string, scores []int) {
+func (m *Mux) MatchAllWithPatternScore(s string, opts ...MatchOption) (vals []interface{}, patterns []string, scores []int) {
+	if len(opts) > 0 || m.sortAll || m.profile || m.hook != nil || m.logger != nil || m.parent != nil {
+		results := m.MatchAllInto(s, nil, opts...)
+		vals = make([]interface{}, len(results))
+		patterns = make([]string, len(results))
+		scores = make([]int, len(results))
+		for i, r := range results {
+			vals[i], patterns[i], scores[i] = r.Value, r.Pattern, r.Score
+		}
+		return
+	}
+
 	m.mtx.RLock()
 	defer m.mtx.RUnlock()
 
-	s = m.trimString(s)
+	raw := s
+	s, ok := m.trimInput(s)
+	if !ok {
+		return
+	}
+	if n := int(m.allHint.Load()); n > 0 {
+		vals = make([]interface{}, 0, n)
+		patterns = make([]string, 0, n)
+		scores = make([]int, 0, n)
+	}
+	var o matchOptions
 	for p, e := range m.m {
-		if ok, score := m.matcher(p, s, e.index); ok {
-			vals = append(vals, e.val)
+		if ok, score := m.match(context.Background(), p, e, raw, s); ok && !o.below(m, score) {
+			if m.maxResults > 0 && len(vals) == m.maxResults {
+				break
+			}
+			m.hit(e)
+			vals = append(vals, m.valueOf(p, e))
 			patterns = append(patterns, p)
 			scores = append(scores, score)
 		}
 	}
+	m.allHint.Store(int64(len(vals)))
 	return
 }
 
+// MatchAllInto appends every match for s to buf[:0] and returns the result,
+// so callers can reuse one buffer across calls without allocating.
+func (m *Mux) MatchAllInto(s string, buf []MatchResult, opts ...MatchOption) []MatchResult {
+	buf, _ = m.matchAll(context.Background(), s, buf, opts)
+	return buf
+}
+
+// matchAll appends every match for s to buf[:0] and reports them to the
+// hook and logger.
+func (m *Mux) matchAll(ctx context.Context, s string, buf []MatchResult, opts []MatchOption) ([]MatchResult, error) {
+	if m.hook == nil && m.logger == nil {
+		return m.scanAll(ctx, s, buf, opts)
+	}
+
+	start := time.Now()
+	buf, err := m.scanAll(ctx, s, buf, opts)
+	if m.debug(ctx) {
+		m.logMatchAll(ctx, s, buf, err)
+	}
+	if m.hook == nil {
+		return buf, err
+	}
+	info := MatchInfo{
+		Input:      s,
+		All:        true,
+		Matched:    len(buf) > 0,
+		Candidates: len(buf),
+		Duration:   time.Since(start),
+		Err:        err,
+	}
+	if err == ErrTruncated {
+		info.Err, info.Truncated = nil, true
+	}
+	if len(buf) > 0 && (m.sortAll || newMatchOptions(opts).paged) {
+		info.Pattern, info.Score = buf[0].Pattern, buf[0].Score
+	}
+	m.hook.OnMatch(ctx, info)
+	return buf, err
+}
+
+// scanAll appends every match for s to buf[:0], checking ctx between
+// entries like scanBest.
+func (m *Mux) scanAll(ctx context.Context, s string, buf []MatchResult, opts []MatchOption) ([]MatchResult, error) {
+	o := newMatchOptions(opts)
+	buf, err := m.collect(ctx, s, buf[:0], &o, m.maxResults, nil)
+	switch err {
+	case nil, ErrTruncated:
+		return o.page(m, buf), err
+	}
+	return buf[:0], err
+}
+
+// collect appends every match for s to buf, then those of the parents not
+// shadowed by a matching entry of a child, stopping with ErrTruncated at
+// max results when positive. As with best, a child entry that is
+// ineligible or does not match s leaves the parent's entry visible.
+func (m *Mux) collect(ctx context.Context, s string, buf []MatchResult, o *matchOptions, max int, shadowed func(p string) bool) ([]MatchResult, error) {
+	if m.profile && !m.labelled(ctx) {
+		return m.collectLabelled(ctx, s, buf, o, max, shadowed)
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	done := ctx.Done()
+	raw := s
+	s, ok, err := m.trimInputErr(s)
+	if !ok {
+		return buf, err
+	}
+	var matched map[string]bool
+	for p, e := range m.m {
+		if done != nil {
+			select {
+			case <-done:
+				return buf, ctx.Err()
+			default:
+			}
+		}
+		if shadowed != nil && shadowed(p) {
+			continue
+		}
+		if ok, score := m.matchIn(ctx, p, e, raw, s); ok && !o.below(m, score) {
+			if max > 0 && len(buf) == max {
+				return buf, ErrTruncated
+			}
+			if m.parent != nil {
+				if matched == nil {
+					matched = make(map[string]bool)
+				}
+				matched[p] = true
+			}
+			m.hit(e)
+			buf = append(buf, MatchResult{
+				Pattern:  p,
+				Value:    m.valueOf(p, e),
+				Score:    score,
+				Index:    e.index,
+				Priority: e.priority,
+				Original: m.originalOf(p, e),
+			})
+		}
+	}
+
+	if m.parent == nil {
+		return buf, nil
+	}
+	return m.parent.collect(ctx, raw, buf, o, max, func(p string) bool {
+		return matched[p] || (shadowed != nil && shadowed(p))
+	})
+}
+
+// MatchAllFunc calls fn for every match for s, in no particular order,
+// until fn returns false. fn runs under the read lock and must not modify
+// the Mux. Entries of a parent Mux are not visited.
+func (m *Mux) MatchAllFunc(s string, fn func(r MatchResult) bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	raw := s
+	s, ok := m.trimInput(s)
+	if !ok {
+		return
+	}
+	var o matchOptions
+	for p, e := range m.m {
+		if ok, score := m.match(context.Background(), p, e, raw, s); ok && !o.below(m, score) {
+			m.hit(e)
+			r := MatchResult{
+				Pattern:  p,
+				Value:    m.valueOf(p, e),
+				Score:    score,
+				Index:    e.index,
+				Priority: e.priority,
+				Original: m.originalOf(p, e),
+			}
+			if !fn(r) {
+				return
+			}
+		}
+	}
+}
+
 type TrimFunc func(s string) string
 
 var NoTrim = func(s string) string {
@@ -207,6 +1289,26 @@ func LongestPatternMatchFn(f MatchFunc) MatchFunc {
 	}
 }
 
+// foldCase maps every rune to the smallest rune of its Unicode simple case
+// folding orbit, so strings equal under strings.EqualFold fold identically.
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, s)
+}
+
+func CaseInsensitiveMatchFn(f MatchFunc) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, score int) {
+		return f(foldCase(pattern), foldCase(s), index)
+	}
+}
+
 func New(c Config) *Mux {
 	if c.TrimPattern == nil {
 		c.TrimPattern = NoTrim
@@ -217,16 +1319,54 @@ func New(c Config) *Mux {
 	if c.Matcher == nil {
 		c.Matcher = StrictMatch
 	}
+	if c.Codec == nil {
+		c.Codec = GobCodec
+	}
 
-	return &Mux{
-		trimPattern: c.TrimPattern,
-		trimString:  c.TrimString,
-		matcher:     c.Matcher,
+	m := &Mux{
+		trimPattern:   c.TrimPattern,
+		trimString:    c.TrimString,
+		trimStringErr: c.TrimStringErr,
+		ctxMatcher:    c.ContextMatcher,
+		matcher:       c.Matcher,
+		tieBreak:      c.TieBreak,
+		compare:       c.CompareScores,
+		scoreBound:    c.ScoreBound,
+		minScore:      c.MinScore,
+		maxResults:    c.MaxResults,
+		fallback:      c.Fallback,
+		onEvict:       c.OnEvict,
+		strict:        c.StrictTies,
+		stats:         c.TrackStats || (c.MaxEntries > 0 && (c.Eviction == EvictLRU || c.Eviction == EvictLFU)),
+		maxEntries:    c.MaxEntries,
+		eviction:      c.Eviction,
+		sortAll:       c.OrderedResults,
+		recent:        newRecentRing(c.RecentMatches),
+		name:          c.Name,
+		profile:       c.ProfileLabels,
+		prefilter:     c.Prefilter,
+		braces:        c.ExpandBraces,
+		vars:          c.Vars,
+		intern:        c.InternPatterns,
+		reconfig:      c.Reconfig,
+		codec:         c.Codec,
+		hook:          c.Hook,
+		logger:        c.Logger,
+		recoverPanics: c.RecoverPanics,
+		onPanic:       c.OnPanic,
+		budget:        c.MatchBudget,
+		onBudget:      c.OnBudgetExceeded,
+		history:       c.History,
 
 		m: make(map[string]*entry),
 	}
+	m.updateExact()
+	return m
 }
 
+// NewStrictMux returns a Mux of exact patterns. Unless Match is given
+// options or the Mux is changed to observe matching, it is a single map
+// read.
 func NewStrictMux() *Mux {
 	return New(Config{})
 }
@@ -236,5 +1376,6 @@ func NewPathMux() *Mux {
 		TrimPattern: PathTrim,
 		TrimString:  PathTrim,
 		Matcher:     PathMatch,
+		ScoreBound:  PatternLengthBound,
 	})
 }
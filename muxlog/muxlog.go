@@ -0,0 +1,160 @@
+// Package muxlog routes log records to sinks by level and component.
+//
+// Routes are keyed by "level:component" patterns. The level is a threshold,
+// so "warn:db" receives warnings, errors and fatal records from component
+// "db". The component is matched exactly, or as a dotted prefix when it ends
+// in ".*", so "error:payment.*" receives errors from "payment" and
+// "payment.card.visa". A component of "*" matches every component.
+package muxlog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/huangml/mux"
+)
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+	Fatal
+)
+
+var levelNames = []string{"debug", "info", "warn", "error", "fatal"}
+
+func (l Level) String() string {
+	if l < Debug || l > Fatal {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+func ParseLevel(s string) (Level, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		return Warn, true
+	}
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), true
+		}
+	}
+	return 0, false
+}
+
+type Record struct {
+	Time      time.Time
+	Level     Level
+	Component string
+	Message   string
+}
+
+type Sink interface {
+	Write(r Record)
+}
+
+type SinkFunc func(r Record)
+
+func (f SinkFunc) Write(r Record) {
+	f(r)
+}
+
+func splitKey(s string) (level, component string) {
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// KeyMatch matches "level:component" inputs against "level:component"
+// patterns as described in the package documentation. More specific
+// components score higher, then higher level thresholds.
+var KeyMatch = func(pattern, s string, index int) (ok bool, score int) {
+	pl, pc := splitKey(pattern)
+	sl, sc := splitKey(s)
+
+	plevel, ok1 := ParseLevel(pl)
+	slevel, ok2 := ParseLevel(sl)
+	if !ok1 || !ok2 || slevel < plevel {
+		return false, 0
+	}
+
+	switch {
+	case pc == "*":
+	case strings.HasSuffix(pc, ".*"):
+		prefix := pc[:len(pc)-2]
+		if sc != prefix && !strings.HasPrefix(sc, prefix+".") {
+			return false, 0
+		}
+		score = 2 * len(prefix)
+	default:
+		if sc != pc {
+			return false, 0
+		}
+		score = 2*len(pc) + 1
+	}
+	return true, score*(int(Fatal)+1) + int(plevel)
+}
+
+type Router struct {
+	m *mux.Mux
+}
+
+func NewRouter() *Router {
+	return &Router{
+		m: mux.New(mux.Config{
+			Matcher: KeyMatch,
+		}),
+	}
+}
+
+// Route registers sink under a "level:component" pattern, replacing any sink
+// previously registered under the same pattern.
+func (r *Router) Route(pattern string, sink Sink) {
+	r.m.Map(pattern, sink)
+}
+
+func (r *Router) Remove(pattern string) {
+	r.m.Delete(pattern)
+}
+
+func key(level Level, component string) string {
+	return level.String() + ":" + component
+}
+
+// Sink returns the most specific sink for records of the given level and
+// component, or nil if none is routed.
+func (r *Router) Sink(level Level, component string) Sink {
+	s, _ := r.m.Match(key(level, component)).(Sink)
+	return s
+}
+
+// Dispatch writes rec to every sink whose route matches it, and reports
+// whether any did.
+func (r *Router) Dispatch(rec Record) bool {
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	written := false
+	for _, v := range r.m.MatchAll(key(rec.Level, rec.Component)) {
+		if s, ok := v.(Sink); ok && s != nil {
+			s.Write(rec)
+			written = true
+		}
+	}
+	return written
+}
+
+// Log is a shorthand for dispatching a record built from its arguments.
+func (r *Router) Log(level Level, component, format string, args ...interface{}) bool {
+	return r.Dispatch(Record{
+		Level:     level,
+		Component: component,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
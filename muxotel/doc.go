@@ -0,0 +1,7 @@
+// Package muxotel records mux matches as OpenTelemetry spans.
+//
+// The adapter depends on go.opentelemetry.io/otel and is only built with the
+// otel build tag:
+//
+//	go build -tags otel
+package muxotel
@@ -0,0 +1,59 @@
+//go:build otel
+
+package muxotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/huangml/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/huangml/mux/muxotel"
+
+type hook struct {
+	tracer trace.Tracer
+}
+
+// Hook returns a mux.Hook recording a span per Match or MatchAll call, a
+// child of the span in the call's context. A nil tp uses the global
+// TracerProvider.
+func Hook(tp trace.TracerProvider) mux.Hook {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return hook{tracer: tp.Tracer(instrumentationName)}
+}
+
+func (h hook) OnMatch(ctx context.Context, info mux.MatchInfo) {
+	name := "mux.Match"
+	if info.All {
+		name = "mux.MatchAll"
+	}
+
+	// the hook runs after the match, so the span is backdated
+	end := time.Now()
+	_, span := h.tracer.Start(ctx, name,
+		trace.WithTimestamp(end.Add(-info.Duration)),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.Bool("mux.matched", info.Matched),
+			attribute.Int("mux.candidates", info.Candidates),
+		),
+	)
+	if info.Pattern != "" {
+		span.SetAttributes(
+			attribute.String("mux.pattern", info.Pattern),
+			attribute.Int("mux.score", info.Score),
+		)
+	}
+	if info.Err != nil {
+		span.RecordError(info.Err)
+		span.SetStatus(codes.Error, info.Err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
@@ -0,0 +1,125 @@
+package muxtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/huangml/mux"
+)
+
+// UpdateEnv names the environment variable that, when set to a non-empty
+// value, makes Golden rewrite golden files instead of comparing them.
+const UpdateEnv = "MUXTEST_UPDATE"
+
+// Snapshot renders the entries of m in index order, one per line, with
+// their value, priority, weight, tags and metadata. The output is stable, so
+// it can be checked in as a golden file.
+func Snapshot(m *mux.Mux) string {
+	var b strings.Builder
+	for _, e := range m.Entries() {
+		fmt.Fprintf(&b, "%d %q => %v", e.Index, e.Pattern, e.Value)
+		if e.Priority != 0 {
+			fmt.Fprintf(&b, " priority=%d", e.Priority)
+		}
+		if e.Weight != 1 {
+			fmt.Fprintf(&b, " weight=%d", e.Weight)
+		}
+		if len(e.Tags) > 0 {
+			fmt.Fprintf(&b, " tags=%s", strings.Join(e.Tags, ","))
+		}
+		keys := make([]string, 0, len(e.Metadata))
+		for k := range e.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, " meta.%s=%v", k, e.Metadata[k])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Golden compares Snapshot(m) with the golden file at path and fails t
+// with a line diff when they differ. With UpdateEnv set, it writes the
+// snapshot to path instead.
+func Golden(t testing.TB, m *mux.Mux, path string) {
+	t.Helper()
+
+	got := Snapshot(m)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("muxtest: %v (set %s=1 to create it)", err, UpdateEnv)
+	}
+	if got != string(want) {
+		t.Errorf("muxtest: route table differs from %s (set %s=1 to update):\n%s", path, UpdateEnv, diffLines(string(want), got))
+	}
+}
+
+// diffLines lists the lines only in want with "-" and those only in got
+// with "+", in order.
+func diffLines(want, got string) string {
+	wl := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	gl := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// longest common subsequence, then walk it
+	lcs := make([][]int, len(wl)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(gl)+1)
+	}
+	for i := len(wl) - 1; i >= 0; i-- {
+		for j := len(gl) - 1; j >= 0; j-- {
+			if wl[i] == gl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(wl) || j < len(gl) {
+		switch {
+		case i < len(wl) && j < len(gl) && wl[i] == gl[j]:
+			i, j = i+1, j+1
+		case j < len(gl) && (i == len(wl) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&b, "+ %s\n", gl[j])
+			j++
+		default:
+			fmt.Fprintf(&b, "- %s\n", wl[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// AssertMatches fails t unless input matches wantPattern in m. An empty
+// wantPattern asserts that input matches nothing.
+func AssertMatches(t testing.TB, m *mux.Mux, input, wantPattern string) {
+	t.Helper()
+
+	val, pattern, score := m.MatchWithPatternScore(input)
+	switch {
+	case wantPattern == "" && (pattern != "" || val != nil):
+		t.Errorf("muxtest: %q matched %q (score %d), want no match", input, pattern, score)
+	case pattern != wantPattern:
+		t.Errorf("muxtest: %q matched %q (score %d), want %q", input, pattern, score, wantPattern)
+	}
+}
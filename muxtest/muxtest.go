@@ -0,0 +1,162 @@
+// Package muxtest provides helpers for testing and fuzzing code built on
+// package mux.
+package muxtest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huangml/mux"
+)
+
+// Corpus returns seed inputs derived from the patterns registered in m:
+// every pattern, its prefixes and suffixes at half length, and variants
+// with a character appended, removed or case-flipped. These sit on the
+// boundaries most matchers care about.
+func Corpus(m *mux.Mux) []string {
+	seen := map[string]bool{"": true}
+	corpus := []string{""}
+	add := func(s string) {
+		if !seen[s] {
+			seen[s] = true
+			corpus = append(corpus, s)
+		}
+	}
+
+	for _, p := range m.Patterns() {
+		add(p)
+		add(p + "/")
+		add(p + "x")
+		if n := len(p); n > 0 {
+			add(p[:n-1])
+			add(p[1:])
+			add(p[:n/2])
+			add(p[n/2:])
+			b := []byte(p)
+			b[0] ^= 0x20
+			add(string(b))
+		}
+	}
+	return corpus
+}
+
+// CheckInvariants matches s against m and returns an error describing the
+// first violated invariant:
+//
+//   - Match finds a winner exactly when MatchAll finds candidates;
+//   - the winner is one of the candidates, with the same score;
+//   - no candidate outranks the winner;
+//   - every candidate is a registered pattern;
+//   - matching s again yields the same winning score.
+//
+// Some configurations are exempt from part of these. Under Config.Fallback
+// Match may win with the fallback of an input MatchAll finds nothing for.
+// Under Config.StrictTies Match fails on ties, and the tied patterns must
+// instead be candidates of equal rank. Under a Mux made by NewChild,
+// candidates may be entries of the parents, which are not checked against
+// a winner of the child. Under Config.MaxResults a capped MatchAll keeps
+// an arbitrary subset of the candidates, so once it holds MaxResults the
+// winner and tied patterns need not be among them.
+//
+// m must not be modified concurrently.
+func CheckInvariants(m *mux.Mux, s string) error {
+	c := m.Config()
+	layered := m.Parent() != nil
+	res, err := m.MatchResult(s)
+	results := m.MatchAllInto(s, nil, mux.Offset(0))
+	capped := c.MaxResults > 0 && len(results) >= c.MaxResults
+
+	var merr *mux.MatchError
+	if c.StrictTies && errors.Is(err, mux.ErrAmbiguous) && errors.As(err, &merr) {
+		return checkTie(s, merr.Patterns, results, capped)
+	}
+	if len(results) == 0 {
+		if err == nil && c.Fallback == nil {
+			return fmt.Errorf("muxtest: %q: Match won with %q (score %d) but MatchAll is empty", s, res.Pattern, res.Score)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("muxtest: %q: MatchAll has %d candidates but Match failed: %v", s, len(results), err)
+	}
+	pattern, score := res.Pattern, res.Score
+
+	var winner *mux.MatchResult
+	for i := range results {
+		if results[i].Pattern == pattern {
+			winner = &results[i]
+			break
+		}
+	}
+	switch {
+	case winner == nil && !capped:
+		return fmt.Errorf("muxtest: %q: Match winner %q is not among the %d MatchAll candidates", s, pattern, len(results))
+	case winner == nil:
+	case winner.Score != score:
+		return fmt.Errorf("muxtest: %q: Match scored %q %d but MatchAll scored it %d", s, pattern, score, winner.Score)
+	case !layered && (results[0].Priority != winner.Priority || results[0].Score != winner.Score):
+		best := results[0]
+		return fmt.Errorf("muxtest: %q: candidate %q (priority %d, score %d) outranks winner %q (priority %d, score %d)",
+			s, best.Pattern, best.Priority, best.Score, pattern, winner.Priority, winner.Score)
+	}
+
+	registered := make(map[string]bool)
+	for l := m; l != nil; l = l.Parent() {
+		for _, p := range l.Patterns() {
+			registered[p] = true
+		}
+	}
+	for _, r := range results {
+		if !registered[r.Pattern] {
+			return fmt.Errorf("muxtest: %q: candidate %q is not a registered pattern", s, r.Pattern)
+		}
+	}
+
+	if again, _ := m.MatchResult(s); again.Score != score {
+		return fmt.Errorf("muxtest: %q: winning score changed from %d to %d between calls", s, score, again.Score)
+	}
+	return nil
+}
+
+// checkTie checks that the patterns of an ambiguous match are candidates
+// of equal priority and score, skipping those missing from capped results.
+func checkTie(s string, tied []string, results []mux.MatchResult, capped bool) error {
+	var first *mux.MatchResult
+	for _, p := range tied {
+		var r *mux.MatchResult
+		for i := range results {
+			if results[i].Pattern == p {
+				r = &results[i]
+				break
+			}
+		}
+		switch {
+		case r == nil && capped:
+		case r == nil:
+			return fmt.Errorf("muxtest: %q: tied pattern %q is not among the %d MatchAll candidates", s, p, len(results))
+		case first == nil:
+			first = r
+		case r.Priority != first.Priority || r.Score != first.Score:
+			return fmt.Errorf("muxtest: %q: tied patterns %q (priority %d, score %d) and %q (priority %d, score %d) differ",
+				s, first.Pattern, first.Priority, first.Score, r.Pattern, r.Priority, r.Score)
+		}
+	}
+	return nil
+}
+
+// Fuzz seeds f with Corpus(m) and fuzzes CheckInvariants:
+//
+//	func FuzzRoutes(f *testing.F) {
+//		muxtest.Fuzz(f, buildRoutes())
+//	}
+func Fuzz(f *testing.F, m *mux.Mux) {
+	for _, s := range Corpus(m) {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if err := CheckInvariants(m, s); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
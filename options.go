@@ -0,0 +1,104 @@
+package mux
+
+import "sort"
+
+// MatchOption adjusts a single Match or MatchAll call. Offset and Limit only
+// apply to MatchAll.
+type MatchOption func(o *matchOptions)
+
+type matchOptions struct {
+	offset int
+	limit  int
+	paged  bool
+
+	minScore    int
+	hasMinScore bool
+
+	affinity    string
+	hasAffinity bool
+}
+
+// Offset skips the first n results.
+func Offset(n int) MatchOption {
+	return func(o *matchOptions) {
+		o.offset, o.paged = n, true
+	}
+}
+
+// Limit returns at most n results.
+func Limit(n int) MatchOption {
+	return func(o *matchOptions) {
+		o.limit, o.paged = n, true
+	}
+}
+
+// MinScore treats matches scoring below n as non-matches, overriding
+// Config.MinScore.
+func MinScore(n int) MatchOption {
+	return func(o *matchOptions) {
+		o.minScore, o.hasMinScore = n, true
+	}
+}
+
+// Affinity makes Match pass key to the tie-break policy in place of the
+// input, e.g. a session id for StickyTieBreak.
+func Affinity(key string) MatchOption {
+	return func(o *matchOptions) {
+		o.affinity, o.hasAffinity = key, true
+	}
+}
+
+func newMatchOptions(opts []MatchOption) (o matchOptions) {
+	o.limit = -1
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return
+}
+
+// below reports whether score ranks below the minimum score in effect.
+func (o *matchOptions) below(m *Mux, score int) bool {
+	if o.hasMinScore {
+		return m.compareScores(score, o.minScore) < 0
+	}
+	return m.minScore != 0 && m.compareScores(score, m.minScore) < 0
+}
+
+// sortResults orders results the way Match ranks them, best first, with
+// the insertion index breaking ties so pages are stable between calls.
+func (m *Mux) sortResults(results []MatchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := &results[i], &results[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if c := m.compareScores(a.Score, b.Score); c != 0 {
+			return c > 0
+		}
+		return a.Index < b.Index
+	})
+}
+
+// page applies Offset and Limit. Paged results are sorted first; results
+// of an unpaged call keep the order in which they were found unless
+// Config.OrderedResults is set.
+func (o matchOptions) page(m *Mux, results []MatchResult) []MatchResult {
+	if !o.paged {
+		if m.sortAll {
+			m.sortResults(results)
+		}
+		return results
+	}
+	m.sortResults(results)
+
+	if o.offset >= len(results) {
+		return results[:0]
+	}
+	if o.offset > 0 {
+		results = results[:copy(results, results[o.offset:])]
+	}
+	if o.limit >= 0 && o.limit < len(results) {
+		results = results[:o.limit]
+	}
+	return results
+}
@@ -0,0 +1,48 @@
+package mux
+
+import "sort"
+
+type bounded struct {
+	pattern string
+	e       *entry
+	bound   int
+
+	// unbounded entries have their own matcher, so the bound says nothing
+	// about them. They sort first and are always scanned.
+	unbounded bool
+}
+
+// PatternLengthBound is a ScoreBound for matchers scoring by pattern length,
+// such as PathMatch, PrefixMatch, SuffixMatch and LongestPatternMatchFn.
+var PatternLengthBound = func(pattern string, index int) int {
+	return len(pattern)
+}
+
+// ordered returns the entries sorted best bound first, rebuilding the index
+// after a mutation. The caller must hold at least the read lock.
+func (m *Mux) ordered() []bounded {
+	m.orderMtx.Lock()
+	defer m.orderMtx.Unlock()
+
+	if m.order == nil {
+		order := make([]bounded, 0, len(m.m))
+		for p, e := range m.m {
+			if e.matcher != nil {
+				order = append(order, bounded{pattern: p, e: e, unbounded: true})
+			} else {
+				order = append(order, bounded{pattern: p, e: e, bound: m.scoreBound(p, e.index) + e.adjust})
+			}
+		}
+		sort.Slice(order, func(i, j int) bool {
+			if order[i].unbounded != order[j].unbounded {
+				return order[i].unbounded
+			}
+			if c := m.rank(order[i].e, order[i].bound, order[j].e, order[j].bound); c != 0 {
+				return c > 0
+			}
+			return order[i].e.index < order[j].e.index
+		})
+		m.order = order
+	}
+	return m.order
+}
@@ -0,0 +1,179 @@
+package mux
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ValueCodec converts entry values and metadata values to and from bytes
+// for Save and Load.
+type ValueCodec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(b []byte) (interface{}, error)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Decode(b []byte) (v interface{}, err error) {
+	err = gob.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	return
+}
+
+// GobCodec encodes values with encoding/gob. Concrete value types must be
+// registered with gob.Register.
+var GobCodec ValueCodec = gobCodec{}
+
+// saveVersion 2 added savedEntry.Rolled, which version 1 tables imply by a
+// Rollout between 0 and 100.
+const saveVersion = 2
+
+type savedTable struct {
+	Version int
+	Index   int
+	Entries []savedEntry
+}
+
+type savedEntry struct {
+	Pattern  string
+	Value    []byte
+	Index    int
+	Weight   int
+	Priority int
+	Metadata map[string][]byte
+	Tags     []string
+	Budget   time.Duration
+	Rollout  float64
+	Rolled   bool
+	Disabled bool
+	Adjust   int
+	Window   *Window
+	AliasOf  string
+	Original string
+}
+
+var ErrUnsaveable = errors.New("mux: entries with their own matcher, trimmer or provider cannot be saved")
+
+// Save writes every entry to w, encoding values and metadata with the
+// Mux's value codec (GobCodec unless set with Config.Codec). The matcher,
+// trimmers and other configuration are not saved: Load into a Mux built
+// with the same Config.
+func (m *Mux) Save(w io.Writer) error {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	t := savedTable{
+		Version: saveVersion,
+		Index:   m.index,
+		Entries: make([]savedEntry, 0, len(m.m)),
+	}
+	for p, e := range m.m {
+		if e.matcher != nil || e.trim != nil || e.lazy != nil {
+			return fmt.Errorf("%w: %q", ErrUnsaveable, p)
+		}
+		if c, ok := m.aliases[p]; ok {
+			t.Entries = append(t.Entries, savedEntry{Pattern: p, AliasOf: c, Original: m.aliasOriginals[p]})
+			continue
+		}
+		val, err := m.codec.Encode(e.val)
+		if err != nil {
+			return fmt.Errorf("mux: encoding value of %q: %w", p, err)
+		}
+		var meta map[string][]byte
+		if e.meta != nil {
+			meta = make(map[string][]byte, len(e.meta))
+			for k, v := range e.meta {
+				if meta[k], err = m.codec.Encode(v); err != nil {
+					return fmt.Errorf("mux: encoding metadata %q of %q: %w", k, p, err)
+				}
+			}
+		}
+		t.Entries = append(t.Entries, savedEntry{
+			Pattern:  p,
+			Value:    val,
+			Index:    e.index,
+			Weight:   e.weight,
+			Priority: e.priority,
+			Metadata: meta,
+			Tags:     e.tags,
+			Budget:   e.budget,
+			Rollout:  e.rollout,
+			Rolled:   e.rolled,
+			Disabled: e.disabled,
+			Adjust:   e.adjust,
+			Window:   e.window.export(),
+			Original: e.original,
+		})
+	}
+	return gob.NewEncoder(w).Encode(&t)
+}
+
+// Load replaces every entry with those read from r, as written by Save.
+// On error the Mux is left unchanged.
+func (m *Mux) Load(r io.Reader) error {
+	var t savedTable
+	if err := gob.NewDecoder(r).Decode(&t); err != nil {
+		return fmt.Errorf("mux: decoding table: %w", err)
+	}
+	if t.Version != saveVersion && t.Version != 1 {
+		return fmt.Errorf("mux: unsupported table version %d", t.Version)
+	}
+
+	m.mtx.RLock()
+	codec := m.codec
+	m.mtx.RUnlock()
+
+	entries := make([]Entry, len(t.Entries))
+	for i, se := range t.Entries {
+		if se.AliasOf != "" {
+			entries[i] = Entry{Pattern: se.Pattern, AliasOf: se.AliasOf}
+			continue
+		}
+		val, err := codec.Decode(se.Value)
+		if err != nil {
+			return fmt.Errorf("mux: decoding value of %q: %w", se.Pattern, err)
+		}
+		var meta map[string]interface{}
+		if se.Metadata != nil {
+			meta = make(map[string]interface{}, len(se.Metadata))
+			for k, b := range se.Metadata {
+				if meta[k], err = codec.Decode(b); err != nil {
+					return fmt.Errorf("mux: decoding metadata %q of %q: %w", k, se.Pattern, err)
+				}
+			}
+		}
+		entries[i] = Entry{
+			Pattern:  se.Pattern,
+			Value:    val,
+			Index:    se.Index,
+			Weight:   se.Weight,
+			Priority: se.Priority,
+			Metadata: meta,
+			Tags:     se.Tags,
+			Budget:   se.Budget,
+			Rollout:  se.Rollout,
+			Rolled:   se.Rolled || (t.Version == 1 && se.Rollout > 0 && se.Rollout < 100),
+			Disabled: se.Disabled,
+			Adjust:   se.Adjust,
+			Window:   se.Window,
+			Original: se.Original,
+		}
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.clear()
+	m.index = t.Index
+	m.importEntries(entries)
+	return nil
+}
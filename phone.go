@@ -0,0 +1,36 @@
+package mux
+
+import "strings"
+
+// PhoneTrim reduces a telephone number to its digits, dropping the
+// international call prefix "00", so "+1 (415) 555-0100" and
+// "0014155550100" both become "14155550100".
+var PhoneTrim = func(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; '0' <= c && c <= '9' {
+			b.WriteByte(c)
+		}
+	}
+	return strings.TrimPrefix(b.String(), "00")
+}
+
+// PhonePrefixMatch matches digit strings against prefix patterns such as
+// the country code "44" or the area code "1415", the longest prefix
+// scoring highest. A Mux using it as its matcher looks up the prefixes of
+// the input instead of scanning its entries, so Match reads at most one
+// key per input digit whatever the size of the table.
+var PhonePrefixMatch = func(pattern, s string, index int) (ok bool, score int) {
+	return pattern != "" && strings.HasPrefix(s, pattern), len(pattern)
+}
+
+// NewPhoneMux returns a Mux routing telephone numbers, normalized with
+// PhoneTrim, by longest prefix.
+func NewPhoneMux() *Mux {
+	return New(Config{
+		TrimPattern: PhoneTrim,
+		TrimString:  PhoneTrim,
+		Matcher:     PhonePrefixMatch,
+	})
+}
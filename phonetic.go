@@ -0,0 +1,87 @@
+package mux
+
+import "strings"
+
+var soundexCodes = [26]byte{
+	// a    b    c    d    e    f    g    h    i    j    k    l    m
+	'0', '1', '2', '3', '0', '1', '2', 'h', '0', '2', '2', '4', '5',
+	// n    o    p    q    r    s    t    u    v    w    x    y    z
+	'5', '0', '1', '2', '6', '2', '3', '0', '1', 'h', '2', '0', '2',
+}
+
+// Soundex returns the American Soundex code of word, e.g. "R163" for both
+// "Robert" and "Rupert". Non-letters are ignored; a word without letters
+// encodes as "".
+func Soundex(word string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for i := 0; i < len(word) && len(code) < 4; i++ {
+		c := word[i] | 0x20
+		if c < 'a' || c > 'z' {
+			continue
+		}
+		d := soundexCodes[c-'a']
+		if len(code) == 0 {
+			code = append(code, c&^0x20)
+			last = d
+			continue
+		}
+		switch d {
+		case 'h':
+			// h and w do not separate letters with the same code
+		case '0':
+			last = d
+		default:
+			if d != last {
+				code = append(code, d)
+			}
+			last = d
+		}
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// PhoneticMatchFn returns a matcher comparing the phonetic encodings of the
+// words in pattern and input. Similarity is the fraction of code positions
+// that agree across all words; inputs with a different number of words never
+// match. The score is the similarity as a percentage, and inputs below
+// minSimilarity (0 to 1) do not match.
+func PhoneticMatchFn(encode func(word string) string, minSimilarity float64) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, score int) {
+		pw, sw := strings.Fields(pattern), strings.Fields(s)
+		if len(pw) == 0 || len(pw) != len(sw) {
+			return false, 0
+		}
+
+		same, total := 0, 0
+		for i := range pw {
+			pc, sc := encode(pw[i]), encode(sw[i])
+			n := len(pc)
+			if len(sc) > n {
+				n = len(sc)
+			}
+			for j := 0; j < len(pc) && j < len(sc); j++ {
+				if pc[j] == sc[j] {
+					same++
+				}
+			}
+			total += n
+		}
+		if total == 0 {
+			return false, 0
+		}
+
+		sim := float64(same) / float64(total)
+		return sim >= minSimilarity, int(sim * 100)
+	}
+}
+
+// PhoneticMatch matches inputs whose Soundex codes agree on at least three
+// quarters of their positions.
+var PhoneticMatch = PhoneticMatchFn(Soundex, 0.75)
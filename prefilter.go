@@ -0,0 +1,95 @@
+package mux
+
+import (
+	"regexp/syntax"
+	"strings"
+)
+
+// PrefilterFunc derives from a pattern a test that every input the pattern
+// can match passes. It returns nil when it knows nothing useful about the
+// pattern.
+type PrefilterFunc func(pattern string) func(s string) bool
+
+func containsAll(literals []string) func(s string) bool {
+	switch len(literals) {
+	case 0:
+		return nil
+	case 1:
+		lit := literals[0]
+		return func(s string) bool {
+			return strings.Contains(s, lit)
+		}
+	}
+	return func(s string) bool {
+		for _, lit := range literals {
+			if !strings.Contains(s, lit) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// requiredLiterals collects case-sensitive literals that appear in every
+// string re matches.
+func requiredLiterals(re *syntax.Regexp) (literals []string) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase == 0 {
+			literals = append(literals, string(re.Rune))
+		}
+	case syntax.OpCapture, syntax.OpPlus:
+		literals = requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			literals = requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+	}
+	return
+}
+
+// RegexPrefilter is a prefilter for RegexMatch. It requires inputs to
+// contain the literals that every match of the pattern contains, so
+// "^/api/v[0-9]+/users" only runs against inputs containing both "/api/v"
+// and "/users".
+var RegexPrefilter = func(pattern string) func(s string) bool {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return containsAll(requiredLiterals(re.Simplify()))
+}
+
+// LikePrefilter is a prefilter for LikeMatch, requiring inputs to contain
+// every literal run between wildcards and to be at least as long as the
+// pattern's fixed characters.
+var LikePrefilter = func(pattern string) func(s string) bool {
+	tokens, _ := compileLike(pattern, '\\')
+
+	var literals []string
+	var run []rune
+	min := 0
+	for _, t := range append(tokens, likeToken{kind: likeAny}) {
+		if t.kind == likeLiteral {
+			run = append(run, t.r)
+		} else if len(run) > 0 {
+			literals = append(literals, string(run))
+			run = run[:0]
+		}
+		if t.kind != likeAny {
+			min++
+		}
+	}
+
+	contains := containsAll(literals)
+	return func(s string) bool {
+		if len(s) < min {
+			return false
+		}
+		return contains == nil || contains(s)
+	}
+}
@@ -0,0 +1,37 @@
+package mux
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// labelled reports whether ctx carries the pprof labels of the Mux.
+func (m *Mux) labelled(ctx context.Context) bool {
+	v, ok := pprof.Label(ctx, "mux")
+	return ok && v == m.name
+}
+
+func (m *Mux) scanBestLabelled(ctx context.Context, s string, o *matchOptions, seen *[]MatchResult) (r MatchResult, found bool, err error) {
+	pprof.Do(ctx, pprof.Labels("mux", m.name), func(ctx context.Context) {
+		r, found, err = m.scanBest(ctx, s, o, seen)
+	})
+	return
+}
+
+func (m *Mux) collectLabelled(ctx context.Context, s string, buf []MatchResult, o *matchOptions, max int, shadowed func(p string) bool) (results []MatchResult, err error) {
+	pprof.Do(ctx, pprof.Labels("mux", m.name), func(ctx context.Context) {
+		results, err = m.collect(ctx, s, buf, o, max, shadowed)
+	})
+	return
+}
+
+// matchIn is match, labelled with the pattern under Config.ProfileLabels.
+func (m *Mux) matchIn(ctx context.Context, p string, e *entry, raw, s string) (ok bool, score int) {
+	if !m.profile {
+		return m.match(ctx, p, e, raw, s)
+	}
+	pprof.Do(ctx, pprof.Labels("mux.pattern", p), func(ctx context.Context) {
+		ok, score = m.match(ctx, p, e, raw, s)
+	})
+	return
+}
@@ -0,0 +1,103 @@
+package mux
+
+import "sort"
+
+// commonPrefixLen returns the length in bytes of the longest common prefix
+// of a and b.
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// LongestCommonPrefix returns the registered pattern sharing the longest
+// prefix with s, regardless of the matcher, e.g. for suggestions and
+// namespace resolution. Patterns and s are compared after trimming, and
+// the earliest registered pattern wins ties. ok is false when no pattern
+// shares a prefix with s, or when trimming rejects s as it would for Match.
+func (m *Mux) LongestCommonPrefix(s string) (pattern string, ok bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if s, ok = m.trimInput(s); !ok {
+		return "", false
+	}
+	n, index := 0, 0
+	for p, e := range m.m {
+		l := commonPrefixLen(p, s)
+		if l > n || (l == n && l > 0 && e.index < index) {
+			pattern, n, index = p, l, e.index
+		}
+	}
+	return pattern, n > 0
+}
+
+// editDistance returns the Levenshtein distance between a and b in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur := min(row[j]+1, row[j-1]+1, prev+cost)
+			prev, row[j] = row[j], cur
+		}
+	}
+	return row[len(rb)]
+}
+
+// Suggest returns up to n registered patterns closest to s by edit
+// distance, closest first, e.g. to offer alternatives when Match fails.
+// Patterns and s are compared after trimming, the earliest registered
+// pattern first on ties. Patterns differing from s in more than half of
+// the longer string's runes are not suggested, and nothing is for inputs
+// trimming rejects as it would for Match.
+func (m *Mux) Suggest(s string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	type suggestion struct {
+		pattern  string
+		distance int
+		index    int
+	}
+	s, ok := m.trimInput(s)
+	if !ok {
+		return nil
+	}
+	var found []suggestion
+	for p, e := range m.m {
+		d := editDistance(p, s)
+		if 2*d <= max(len([]rune(p)), len([]rune(s))) {
+			found = append(found, suggestion{p, d, e.index})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		return found[i].index < found[j].index
+	})
+
+	patterns := make([]string, 0, min(n, len(found)))
+	for _, f := range found[:min(n, len(found))] {
+		patterns = append(patterns, f.pattern)
+	}
+	return patterns
+}
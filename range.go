@@ -0,0 +1,113 @@
+package mux
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type numRange struct {
+	lo, hi       float64
+	loInc, hiInc bool
+}
+
+func (r numRange) contains(v float64) bool {
+	if math.IsNaN(v) {
+		return false
+	}
+	if v < r.lo || (v == r.lo && !r.loInc) {
+		return false
+	}
+	if v > r.hi || (v == r.hi && !r.hiInc) {
+		return false
+	}
+	return true
+}
+
+// score ranks narrower ranges higher: an exact number scores 0, a bounded
+// range scores minus its width and a half-open range scores lowest.
+func (r numRange) score() int {
+	w := r.hi - r.lo
+	if math.IsInf(w, 0) || w >= math.MaxInt32 {
+		return math.MinInt32
+	}
+	return -int(math.Ceil(w))
+}
+
+// parseFinite parses s as a number, rejecting NaN, which no comparison
+// can place, and infinities, which make no bound.
+func parseFinite(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func parseNumRange(pattern string) (r numRange, ok bool) {
+	pattern = strings.TrimSpace(pattern)
+	inf := math.Inf(1)
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if !strings.HasPrefix(pattern, op) {
+			continue
+		}
+		v, ok := parseFinite(pattern[len(op):])
+		if !ok {
+			return r, false
+		}
+		switch op {
+		case ">=":
+			return numRange{v, inf, true, false}, true
+		case ">":
+			return numRange{v, inf, false, false}, true
+		case "<=":
+			return numRange{-inf, v, false, true}, true
+		case "<":
+			return numRange{-inf, v, false, false}, true
+		default:
+			return numRange{v, v, true, true}, true
+		}
+	}
+
+	// lo-hi, where either bound may itself be negative.
+	for i := 1; i < len(pattern); i++ {
+		if pattern[i] != '-' || pattern[i-1] == 'e' || pattern[i-1] == 'E' || pattern[i-1] == '-' {
+			continue
+		}
+		lo, ok1 := parseFinite(pattern[:i])
+		hi, ok2 := parseFinite(pattern[i+1:])
+		if !ok1 || !ok2 || lo > hi {
+			return r, false
+		}
+		return numRange{lo, hi, true, true}, true
+	}
+
+	v, ok := parseFinite(pattern)
+	if !ok {
+		return r, false
+	}
+	return numRange{v, v, true, true}, true
+}
+
+// RangeMatch matches numeric inputs against range patterns such as "404",
+// "100-199", ">=500" or "<0". Narrower ranges score higher, so "404" beats
+// "400-499" which beats ">=400". NaN and infinities match nothing, in
+// patterns or inputs.
+var RangeMatch = func(pattern, s string, index int) (ok bool, score int) {
+	r, ok := parseNumRange(pattern)
+	if !ok {
+		return false, 0
+	}
+	v, ok := parseFinite(s)
+	if !ok {
+		return false, 0
+	}
+	return r.contains(v), r.score()
+}
+
+func NewRangeMux() *Mux {
+	return New(Config{
+		Matcher: RangeMatch,
+	})
+}
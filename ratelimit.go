@@ -0,0 +1,55 @@
+package mux
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitKey is the metadata key of the Limiter an HTTPHandler consults
+// before serving a request matched by the entry.
+const RateLimitKey = "ratelimit"
+
+// Limiter decides whether a request may be served now. *rate.Limiter from
+// golang.org/x/time/rate is one.
+type Limiter interface {
+	Allow() bool
+}
+
+// LimitStatus is optionally implemented by a Limiter to report its state
+// in the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers,
+// and in Retry-After when a request is refused.
+type LimitStatus interface {
+	Status() (limit, remaining int, reset time.Duration)
+}
+
+// allow consults the Limiter of the entry mapped under pattern, if any,
+// answering 429 Too Many Requests when it refuses the request.
+func (h *HTTPHandler) allow(w http.ResponseWriter, pattern string) bool {
+	v, ok := h.Mux.metaValue(pattern, RateLimitKey)
+	if !ok {
+		return true
+	}
+	l, ok := v.(Limiter)
+	if !ok {
+		return true
+	}
+
+	allowed := l.Allow()
+	if st, ok := l.(LimitStatus); ok {
+		limit, remaining, reset := st.Status()
+		secs := strconv.Itoa(int(math.Ceil(reset.Seconds())))
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		h.Set("RateLimit-Reset", secs)
+		if !allowed {
+			h.Set("Retry-After", secs)
+		}
+	}
+	if !allowed {
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	}
+	return allowed
+}
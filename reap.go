@@ -0,0 +1,57 @@
+package mux
+
+import (
+	"sync"
+	"time"
+)
+
+// Reap deletes the entries that have not matched for idle, counting from
+// their creation for entries that never matched, and returns their
+// patterns. Disabled entries are kept. Values are reported to
+// Config.OnEvict. It needs Config.TrackStats and reaps nothing otherwise.
+func (m *Mux) Reap(idle time.Duration) []string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if !m.stats {
+		return nil
+	}
+	cutoff := time.Now().Add(-idle).UnixNano()
+	var reaped []string
+	for p, e := range m.m {
+		if _, alias := m.aliases[p]; alias || e.disabled {
+			continue
+		}
+		if e.lastUse() < cutoff {
+			m.remove(p, e)
+			reaped = append(reaped, p)
+		}
+	}
+	if len(reaped) > 0 {
+		m.order = nil
+	}
+	return reaped
+}
+
+// StartReaper calls Reap(idle) every interval in a new goroutine until the
+// returned stop function is called.
+func (m *Mux) StartReaper(idle, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				m.Reap(idle)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
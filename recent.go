@@ -0,0 +1,65 @@
+package mux
+
+import (
+	"sync"
+	"time"
+)
+
+// RecentMatch records one Match call, see Config.RecentMatches.
+type RecentMatch struct {
+	Time     time.Time
+	Input    string
+	Pattern  string
+	Score    int
+	Matched  bool
+	Duration time.Duration
+}
+
+type recentRing struct {
+	mtx  sync.Mutex
+	buf  []RecentMatch
+	next int
+	full bool
+}
+
+func newRecentRing(n int) *recentRing {
+	if n <= 0 {
+		return nil
+	}
+	return &recentRing{buf: make([]RecentMatch, n)}
+}
+
+func (r *recentRing) size() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.buf)
+}
+
+func (r *recentRing) add(rm RecentMatch) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.buf[r.next] = rm
+	r.next++
+	if r.next == len(r.buf) {
+		r.next, r.full = 0, true
+	}
+}
+
+// RecentMatches returns the latest Match calls kept under
+// Config.RecentMatches, oldest first.
+func (m *Mux) RecentMatches() []RecentMatch {
+	r := m.recent
+	if r == nil {
+		return nil
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if !r.full {
+		return append([]RecentMatch(nil), r.buf[:r.next]...)
+	}
+	return append(append(make([]RecentMatch, 0, len(r.buf)), r.buf[r.next:]...), r.buf[:r.next]...)
+}
@@ -0,0 +1,95 @@
+package mux
+
+// ReconfigPolicy decides how a Mux handles matcher and trimmer changes after
+// entries have been mapped, when the keys were trimmed and may have been
+// matched under the old configuration.
+type ReconfigPolicy int
+
+const (
+	// ReconfigAllow applies changes without touching existing entries.
+	ReconfigAllow ReconfigPolicy = iota
+
+	// ReconfigReject makes the setters return ErrFrozen once entries exist.
+	ReconfigReject
+
+	// ReconfigRetrim applies changes and trims existing keys again with a
+	// new pattern trimmer. Keys that collide after trimming keep the entry
+	// registered last, as if mapped again in order.
+	ReconfigRetrim
+)
+
+// reconfigure checks whether the configuration may change. The caller must
+// hold the write lock.
+func (m *Mux) reconfigure() error {
+	if m.reconfig == ReconfigReject && len(m.m) > 0 {
+		return ErrFrozen
+	}
+	return nil
+}
+
+// retrim rebuilds the entry map with keys trimmed by the current pattern
+// trimmer from their original spelling, leaving entries with their own
+// trimmer alone. The caller must hold the write lock.
+func (m *Mux) retrim() {
+	old, oldAliases, oldOriginals := m.m, m.aliases, m.aliasOriginals
+	m.m = make(map[string]*entry, len(old))
+	m.aliases, m.aliasOriginals = nil, nil
+	var moved map[string]string // old canonical keys of aliases to new ones
+	if len(oldAliases) > 0 {
+		moved = make(map[string]string)
+	}
+	for p, e := range old {
+		if _, ok := oldAliases[p]; ok {
+			continue
+		}
+		oldKey := p
+		if e.trim == nil {
+			// trimming the original spelling undoes the old trimmer
+			orig := m.originalOf(p, e)
+			p, e.original = m.trimPattern(orig), ""
+			if p != orig {
+				e.original = orig
+			}
+		}
+		if cur, ok := m.m[p]; ok {
+			if cur.index > e.index {
+				m.dropRetrimmed(p, e)
+				continue
+			}
+			m.dropRetrimmed(p, cur)
+		}
+		if m.intern {
+			p = Intern(p)
+		}
+		m.m[p] = e
+		if moved != nil {
+			moved[oldKey] = p
+		}
+	}
+	for a, c := range oldAliases {
+		orig, ok := oldOriginals[a]
+		if !ok {
+			orig = a
+		}
+		ckey, ok := moved[c]
+		if e, found := m.m[ckey]; ok && found && e == old[c] {
+			m.alias(m.trimPattern(orig), ckey, e, orig)
+		}
+	}
+	m.order = nil
+	if m.prefilter != nil {
+		m.setPrefilter(m.prefilter)
+	}
+}
+
+// dropRetrimmed evicts e, which lost the key p to another entry when
+// retrimmed, no longer counting its own matcher and trimmer.
+func (m *Mux) dropRetrimmed(p string, e *entry) {
+	if e.trim != nil {
+		m.ownTrims--
+	}
+	if e.matcher != nil {
+		m.ownMatchers--
+	}
+	m.evict(p, e)
+}
@@ -0,0 +1,67 @@
+package mux
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// PanicError describes a panic recovered while matching, see
+// Config.RecoverPanics.
+type PanicError struct {
+	// Pattern is the entry being matched, or "" when the string trimmer
+	// panicked.
+	Pattern string
+	Input   string
+	Value   interface{}
+	Stack   []byte
+}
+
+func (e *PanicError) Error() string {
+	if e.Pattern == "" {
+		return fmt.Sprintf("mux: panic trimming %q: %v", e.Input, e.Value)
+	}
+	return fmt.Sprintf("mux: panic matching %q against %q: %v", e.Input, e.Pattern, e.Value)
+}
+
+// recoverPanic reports a panic raised while matching s against pattern.
+// It must be deferred directly.
+func (m *Mux) recoverPanic(pattern, s string) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	m.reportPanic(&PanicError{Pattern: pattern, Input: s, Value: v, Stack: debug.Stack()})
+}
+
+// reportPanic passes err to OnPanic, or logs it.
+func (m *Mux) reportPanic(err *PanicError) {
+	switch {
+	case m.onPanic != nil:
+		m.onPanic(err)
+	case m.logger != nil:
+		m.logger.Error(err.Error(), slog.String("stack", string(err.Stack)))
+	}
+}
+
+// trimInput trims s with the string trimmers, reporting false when they
+// panicked under Config.RecoverPanics or rejected s.
+func (m *Mux) trimInput(s string) (t string, ok bool) {
+	t, ok, _ = m.trimInputErr(s)
+	return
+}
+
+// trimInputErr is trimInput also returning a *TrimError for inputs
+// rejected by Config.TrimStringErr.
+func (m *Mux) trimInputErr(s string) (t string, ok bool, err error) {
+	if m.recoverPanics {
+		defer m.recoverPanic("", s)
+	}
+	t = s
+	if m.trimStringErr != nil {
+		if t, err = m.trimStringErr(s); err != nil {
+			return "", false, &TrimError{Input: s, Err: err}
+		}
+	}
+	return m.trimString(t), true, nil
+}
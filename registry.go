@@ -0,0 +1,86 @@
+package mux
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	registryMtx sync.RWMutex
+	registry    = make(map[string]*Mux)
+)
+
+// Register makes m available by name through Lookup, so tables can be
+// shared between packages. Like sql.Register, it panics when name is
+// already registered or m is nil.
+func Register(name string, m *Mux) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	if m == nil {
+		panic("mux: Register of nil Mux " + name)
+	}
+	if _, ok := registry[name]; ok {
+		panic("mux: Register called twice for " + name)
+	}
+	registry[name] = m
+}
+
+// Unregister removes the Mux registered under name.
+func Unregister(name string) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	delete(registry, name)
+}
+
+// Lookup returns the Mux registered under name.
+func Lookup(name string) (*Mux, bool) {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+
+	m, ok := registry[name]
+	return m, ok
+}
+
+// Registered returns the sorted names of the registered Muxes.
+func Registered() []string {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultMux is the path Mux used by the package-level Map and Match
+// functions, for programs needing a single table.
+var DefaultMux = NewPathMux()
+
+// Map maps pattern to val in DefaultMux.
+func Map(pattern string, val interface{}) {
+	DefaultMux.Map(pattern, val)
+}
+
+// Delete deletes pattern from DefaultMux.
+func Delete(pattern string) {
+	DefaultMux.Delete(pattern)
+}
+
+// Match matches s against DefaultMux.
+func Match(s string, opts ...MatchOption) interface{} {
+	return DefaultMux.Match(s, opts...)
+}
+
+// MatchWithPattern matches s against DefaultMux.
+func MatchWithPattern(s string, opts ...MatchOption) (val interface{}, pattern string) {
+	return DefaultMux.MatchWithPattern(s, opts...)
+}
+
+// MatchAll matches s against DefaultMux.
+func MatchAll(s string, opts ...MatchOption) []interface{} {
+	return DefaultMux.MatchAll(s, opts...)
+}
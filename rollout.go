@@ -0,0 +1,35 @@
+package mux
+
+// inRollout reports whether s falls in the first percent of inputs, as
+// spread by a stable hash.
+func inRollout(s string, percent float64) bool {
+	return float64(hash32(s))*100 < percent*(1<<32)
+}
+
+// MapWithRollout maps pattern to val for percent of the inputs it matches,
+// chosen by a stable hash of the trimmed input, so the same input is always
+// in or out. The other inputs fall through to the next best match, e.g. to
+// canary a new value under a more specific pattern than the stable one. A
+// percent of 100 or more serves every input and 0 or less none.
+func (m *Mux) MapWithRollout(pattern string, val interface{}, percent float64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, p := range m.expand(pattern) {
+		if e := m.mapEntry(p, val); e != nil {
+			e.rollout, e.rolled = percent, percent < 100
+		}
+	}
+}
+
+// RolloutFn returns a matcher matching like f for percent of the inputs,
+// chosen by a stable hash of the input, and matching nothing otherwise. See
+// MapWithRollout.
+func RolloutFn(f MatchFunc, percent float64) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, score int) {
+		if !inRollout(s, percent) {
+			return false, 0
+		}
+		return f(pattern, s, index)
+	}
+}
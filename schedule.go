@@ -0,0 +1,226 @@
+package mux
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleTimeFormat is the layout ScheduleMatch expects its input in.
+var ScheduleTimeFormat = time.RFC3339Nano
+
+type schedule struct {
+	minutes  [24 * 60]bool
+	dom      [32]bool
+	month    [13]bool
+	dow      [7]bool
+	domStar  bool
+	dowStar  bool
+	coverage int
+}
+
+func (sc *schedule) contains(t time.Time) bool {
+	if !sc.minutes[t.Hour()*60+t.Minute()] || !sc.month[int(t.Month())] {
+		return false
+	}
+	dom, dow := sc.dom[t.Day()], sc.dow[int(t.Weekday())]
+	switch {
+	case sc.domStar && sc.dowStar:
+		return true
+	case sc.domStar:
+		return dow
+	case sc.dowStar:
+		return dom
+	default:
+		// cron semantics: restricted day-of-month and day-of-week are ORed
+		return dom || dow
+	}
+}
+
+func countSet(set []bool) (n int) {
+	for _, b := range set {
+		if b {
+			n++
+		}
+	}
+	return
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+var monthNames = []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+func parseFieldValue(s string, names []string) (int, bool) {
+	s = strings.ToLower(s)
+	for i, name := range names {
+		if name != "" && s == name {
+			return i, true
+		}
+	}
+	v, err := strconv.Atoi(s)
+	return v, err == nil
+}
+
+// parseField parses one cron field ("*", "5", "1-5", "*/15", "1-10/2" or a
+// comma separated list of those) into set, whose valid indexes are min..max.
+func parseField(field string, set []bool, min, max int, names []string) bool {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			v, err := strconv.Atoi(part[i+1:])
+			if err != nil || v <= 0 {
+				return false
+			}
+			part, step = part[:i], v
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			var ok bool
+			if i := strings.IndexByte(part, '-'); i > 0 {
+				if lo, ok = parseFieldValue(part[:i], names); !ok {
+					return false
+				}
+				if hi, ok = parseFieldValue(part[i+1:], names); !ok {
+					return false
+				}
+			} else {
+				if lo, ok = parseFieldValue(part, names); !ok {
+					return false
+				}
+				hi = lo
+				if step > 1 {
+					hi = max
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return false
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return true
+}
+
+func parseCron(fields []string) (*schedule, bool) {
+	sc := &schedule{}
+	var minutes [60]bool
+	var hours [24]bool
+	var dow [8]bool
+	if !parseField(fields[0], minutes[:], 0, 59, nil) ||
+		!parseField(fields[1], hours[:], 0, 23, nil) ||
+		!parseField(fields[2], sc.dom[:], 1, 31, nil) ||
+		!parseField(fields[3], sc.month[:], 1, 12, monthNames) ||
+		!parseField(fields[4], dow[:], 0, 7, weekdayNames) {
+		return nil, false
+	}
+	for h := range hours {
+		for m := range minutes {
+			sc.minutes[h*60+m] = hours[h] && minutes[m]
+		}
+	}
+	copy(sc.dow[:], dow[:7])
+	sc.dow[0] = sc.dow[0] || dow[7]
+	sc.domStar, sc.dowStar = fields[2] == "*", fields[4] == "*"
+	return sc, true
+}
+
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// parseWindow parses "[days] HH:MM-HH:MM", e.g. "Mon-Fri 09:00-17:00" or
+// "22:00-06:00". The end is exclusive and windows may wrap past midnight.
+func parseWindow(fields []string) (*schedule, bool) {
+	sc := &schedule{domStar: true}
+	for i := range sc.dom {
+		sc.dom[i] = i > 0
+	}
+	for i := range sc.month {
+		sc.month[i] = i > 0
+	}
+
+	switch len(fields) {
+	case 1:
+		sc.dowStar = true
+		for i := range sc.dow {
+			sc.dow[i] = true
+		}
+	case 2:
+		if !parseField(fields[0], sc.dow[:], 0, 6, weekdayNames) {
+			return nil, false
+		}
+		fields = fields[1:]
+	default:
+		return nil, false
+	}
+
+	i := strings.IndexByte(fields[0], '-')
+	if i < 0 {
+		return nil, false
+	}
+	from, ok1 := parseClock(fields[0][:i])
+	to, ok2 := parseClock(fields[0][i+1:])
+	if !ok1 || !ok2 || from == to {
+		return nil, false
+	}
+	for m := from; m != to; m = (m + 1) % len(sc.minutes) {
+		sc.minutes[m] = true
+	}
+	return sc, true
+}
+
+func parseSchedule(pattern string) (*schedule, bool) {
+	fields := strings.Fields(pattern)
+
+	var sc *schedule
+	var ok bool
+	if len(fields) == 5 {
+		sc, ok = parseCron(fields)
+	} else {
+		sc, ok = parseWindow(fields)
+	}
+	if !ok {
+		return nil, false
+	}
+	sc.coverage = countSet(sc.minutes[:]) * countSet(sc.dow[:]) * countSet(sc.dom[1:]) * countSet(sc.month[1:])
+	return sc, true
+}
+
+// ScheduleMatch matches timestamps formatted with ScheduleTimeFormat against
+// time windows ("09:00-17:00", "Mon-Fri 09:00-17:00") or five-field cron
+// expressions ("*/15 9-17 * * mon-fri"). Patterns covering less time score
+// higher, so a holiday window beats the everyday default.
+var ScheduleMatch = func(pattern, s string, index int) (ok bool, score int) {
+	sc, ok := parseSchedule(pattern)
+	if !ok {
+		return false, 0
+	}
+	t, err := time.Parse(ScheduleTimeFormat, s)
+	if err != nil {
+		return false, 0
+	}
+	return sc.contains(t), -sc.coverage
+}
+
+func NewScheduleMux() *Mux {
+	return New(Config{
+		Matcher: ScheduleMatch,
+	})
+}
+
+// MatchTime returns the value whose schedule is active at t.
+func (m *Mux) MatchTime(t time.Time) (val interface{}) {
+	return m.Match(t.Format(ScheduleTimeFormat))
+}
+
+// MatchNow returns the value whose schedule is active now.
+func (m *Mux) MatchNow() (val interface{}) {
+	return m.MatchTime(time.Now())
+}
@@ -0,0 +1,95 @@
+package mux
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Float64Score encodes f as an int score such that comparing scores as ints
+// orders them as the original floats, so matchers with fractional scores
+// need no lossy scaling. NaN ranks above every other value. It assumes a
+// 64-bit int.
+func Float64Score(f float64) int {
+	b := math.Float64bits(f)
+	if b>>63 == 1 {
+		b = ^b
+	} else {
+		b |= 1 << 63
+	}
+	return int(int64(b ^ 1<<63))
+}
+
+// ScoreFloat64 decodes a score produced by Float64Score.
+func ScoreFloat64(score int) float64 {
+	b := uint64(int64(score)) ^ 1<<63
+	if b>>63 == 1 {
+		b &^= 1 << 63
+	} else {
+		b = ^b
+	}
+	return math.Float64frombits(b)
+}
+
+// ReverseScores is a CompareScores function ranking lower scores above
+// higher ones, for matchers that score by distance or cost.
+var ReverseScores = func(a, b int) int {
+	switch {
+	case a < b:
+		return 1
+	case a > b:
+		return -1
+	}
+	return 0
+}
+
+// NormalScale is the top of the common scale used by NormalizeFn,
+// CoverageScoreFn and FixedScoreFn, whose scores run from 0 to NormalScale.
+// Mapping every matcher of a mixed table onto it lets, say, a prefix
+// matcher's length scores and a regexp matcher's index scores rank
+// sensibly against each other:
+//
+//	m.MapWithMatcher(`^/u/\d+$`, v, FixedScoreFn(RegexMatch, NormalScale/2))
+const NormalScale = 1 << 20
+
+func scaled(num, den int) int {
+	switch {
+	case den <= 0 || num >= den:
+		return NormalScale
+	case num <= 0:
+		return 0
+	}
+	// num < den keeps the high word of the product below den, as Div64
+	// requires, so large scores cannot overflow
+	hi, lo := bits.Mul64(uint64(num), NormalScale)
+	q, _ := bits.Div64(hi, lo, uint64(den))
+	return int(q)
+}
+
+// NormalizeFn maps f's scores from [min, max] linearly onto [0,
+// NormalScale], clamping scores outside the range.
+func NormalizeFn(f MatchFunc, min, max int) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, score int) {
+		ok, score = f(pattern, s, index)
+		return ok, scaled(score-min, max-min)
+	}
+}
+
+// CoverageScoreFn scores f's matches by the fraction of the input covered by
+// the pattern, on [0, NormalScale]: a pattern as long as the input scores
+// NormalScale. It suits length-scored matchers such as PrefixMatch and
+// SuffixMatch, whose raw scores depend on the input length.
+func CoverageScoreFn(f MatchFunc) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, score int) {
+		ok, _ = f(pattern, s, index)
+		return ok, scaled(len(pattern), len(s))
+	}
+}
+
+// FixedScoreFn gives every match of f the same score, for matchers such as
+// RegexMatch whose scores carry no quality information.
+func FixedScoreFn(f MatchFunc, score int) MatchFunc {
+	return func(pattern, s string, index int) (ok bool, _ int) {
+		ok, _ = f(pattern, s, index)
+		return ok, score
+	}
+}
@@ -0,0 +1,50 @@
+package mux
+
+// shadowTable returns the table of shadow entries, creating it configured
+// like m, without its hook and recent matches, when create is set.
+func (m *Mux) shadowTable(create bool) *Mux {
+	m.mtx.RLock()
+	shadow := m.shadow
+	m.mtx.RUnlock()
+	if shadow != nil || !create {
+		return shadow
+	}
+
+	c := m.Config()
+	c.Hook = nil
+	c.RecentMatches = 0
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.shadow == nil {
+		m.shadow = New(c)
+	}
+	return m.shadow
+}
+
+// MapShadow maps pattern to val as a shadow entry, kept apart from the
+// other entries so it can share their patterns. Shadow entries never win a
+// match, but MatchWithShadows returns those matching along with the
+// winner, e.g. to mirror traffic to a new handler without affecting the
+// response.
+func (m *Mux) MapShadow(pattern string, val interface{}) {
+	m.shadowTable(true).Map(pattern, val)
+}
+
+// DeleteShadow deletes the shadow entry mapped under pattern.
+func (m *Mux) DeleteShadow(pattern string) {
+	if shadow := m.shadowTable(false); shadow != nil {
+		shadow.Delete(pattern)
+	}
+}
+
+// MatchWithShadows is MatchResult also returning every shadow entry
+// matching s, ranked best first. Shadows are returned whether or not there
+// is a winner.
+func (m *Mux) MatchWithShadows(s string, opts ...MatchOption) (r MatchResult, shadows []MatchResult, err error) {
+	r, err = m.MatchResult(s, opts...)
+	if shadow := m.shadowTable(false); shadow != nil {
+		shadows = shadow.MatchAllInto(s, nil, append(opts[:len(opts):len(opts)], Offset(0))...)
+	}
+	return r, shadows, err
+}
@@ -0,0 +1,108 @@
+package mux
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// sniffLen is the most bytes SniffMIME looks at, as with
+// http.DetectContentType.
+const sniffLen = 512
+
+type magic struct {
+	offset int
+	sig    string
+	mime   string
+}
+
+// magics are signatures http.DetectContentType does not know, or reports
+// as a less specific type.
+var magics = []magic{
+	{4, "ftypheic", "image/heic"},
+	{4, "ftypheix", "image/heic"},
+	{4, "ftypmif1", "image/heif"},
+	{4, "ftypavif", "image/avif"},
+	{0, "II*\x00", "image/tiff"},
+	{0, "MM\x00*", "image/tiff"},
+	{0, "fLaC", "audio/flac"},
+	{0, "\x28\xb5\x2f\xfd", "application/zstd"},
+	{0, "BZh", "application/x-bzip2"},
+	{0, "\xfd7zXZ\x00", "application/x-xz"},
+	{0, "7z\xbc\xaf\x27\x1c", "application/x-7z-compressed"},
+	{0, "PAR1", "application/vnd.apache.parquet"},
+	{0, "SQLite format 3\x00", "application/vnd.sqlite3"},
+	{0, "\x7fELF", "application/x-elf"},
+	{0, "\xcf\xfa\xed\xfe", "application/x-mach-binary"},
+	{0, "\xfe\xed\xfa\xcf", "application/x-mach-binary"},
+	{0, "MZ", "application/vnd.microsoft.portable-executable"},
+}
+
+// SniffMIME returns the media type of data judging by its first bytes:
+// that of a known magic number, or else that of http.DetectContentType,
+// which falls back to "application/octet-stream".
+func SniffMIME(data []byte) string {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	for _, m := range magics {
+		if len(data) >= m.offset+len(m.sig) && string(data[m.offset:m.offset+len(m.sig)]) == m.sig {
+			return m.mime
+		}
+	}
+	return http.DetectContentType(data)
+}
+
+// SniffReader is SniffMIME reading the start of r, returning a reader of
+// the whole of r for the payload to be consumed in full.
+func SniffReader(r io.Reader) (mime string, payload io.Reader, err error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	return SniffMIME(head), io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// ContentRouter routes payloads by their sniffed media type, matched with
+// MIMEMatch, to processors.
+type ContentRouter struct {
+	m *Mux
+}
+
+// NewContentRouter returns an empty ContentRouter.
+func NewContentRouter() *ContentRouter {
+	return &ContentRouter{m: NewMIMEMux()}
+}
+
+// Handle routes payloads of the media types of pattern, such as
+// "image/*" or "application/*+json", to f.
+func (c *ContentRouter) Handle(pattern string, f func(mime string, payload io.Reader) error) {
+	c.m.Map(pattern, f)
+}
+
+// Route passes data to the processor of its media type, returning its
+// error, or a MatchError if there is none.
+func (c *ContentRouter) Route(data []byte) error {
+	mime := SniffMIME(data)
+	res, err := c.m.MatchResult(mime)
+	if err != nil {
+		return err
+	}
+	return res.Value.(func(string, io.Reader) error)(mime, bytes.NewReader(data))
+}
+
+// RouteReader is Route reading the payload from r, which the processor
+// reads on from the sniffed bytes.
+func (c *ContentRouter) RouteReader(r io.Reader) error {
+	mime, payload, err := SniffReader(r)
+	if err != nil {
+		return err
+	}
+	res, err := c.m.MatchResult(mime)
+	if err != nil {
+		return err
+	}
+	return res.Value.(func(string, io.Reader) error)(mime, payload)
+}
@@ -0,0 +1,147 @@
+package mux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spec describes a Config by the names its matcher and trimmers are
+// registered under, for tools that cannot call Go:
+//
+//	{"trim": "path|lowercase", "match": "longest(prefix)", "tieBreak": "first"}
+//
+// Trim applies to both patterns and inputs unless TrimPattern or
+// TrimString is set. TieBreak is one of first, last, longest, random, roundrobin
+// and sticky. Empty fields keep the defaults of New, and the others set
+// the Config fields of the same name.
+type Spec struct {
+	Trim        string `json:"trim,omitempty"`
+	TrimPattern string `json:"trimPattern,omitempty"`
+	TrimString  string `json:"trimString,omitempty"`
+	Match       string `json:"match,omitempty"`
+	TieBreak    string `json:"tieBreak,omitempty"`
+
+	StrictTies     bool `json:"strictTies,omitempty"`
+	MinScore       int  `json:"minScore,omitempty"`
+	MaxResults     int  `json:"maxResults,omitempty"`
+	ExpandBraces   bool `json:"expandBraces,omitempty"`
+	OrderedResults bool `json:"orderedResults,omitempty"`
+}
+
+// matchWrappers are the wrappers ParseMatcher accepts around a matcher.
+var matchWrappers = map[string]func(MatchFunc) MatchFunc{
+	"first":    FirstMatchFn,
+	"last":     LastMatchFn,
+	"shortest": ShortestPatternMatchFn,
+	"longest":  LongestPatternMatchFn,
+	"nocase":   CaseInsensitiveMatchFn,
+	"coverage": CoverageScoreFn,
+}
+
+// tieBreaks are the policies a Spec can name, made anew for every Config
+// so round-robin rotation is not shared.
+var tieBreaks = map[string]func() TieBreakFunc{
+	"first":      func() TieBreakFunc { return FirstRegistered },
+	"last":       func() TieBreakFunc { return LastRegistered },
+	"longest":    func() TieBreakFunc { return LongestPattern },
+	"random":     func() TieBreakFunc { return WeightedRandomTieBreak },
+	"sticky":     func() TieBreakFunc { return StickyTieBreak },
+	"roundrobin": RoundRobinTieBreakFn,
+}
+
+// ParseTrimmer returns the trimmer described by spec, a "|" separated list
+// of registered trimmer names applied left to right.
+func ParseTrimmer(spec string) (TrimFunc, error) {
+	var f TrimFunc
+	for _, name := range strings.Split(spec, "|") {
+		name = strings.TrimSpace(name)
+		g, ok := LookupTrimmer(name)
+		if !ok {
+			return nil, &SpecError{Spec: spec, Err: fmt.Errorf("unknown trimmer %q", name)}
+		}
+		if f == nil {
+			f = g
+		} else {
+			f = CombineTrimFn(g, f)
+		}
+	}
+	return f, nil
+}
+
+// ParseMatcher returns the matcher described by spec, a registered matcher
+// name optionally wrapped in first, last, shortest, longest, nocase or
+// coverage, e.g. "nocase(longest(prefix))".
+func ParseMatcher(spec string) (MatchFunc, error) {
+	f, err := parseMatcher(spec)
+	if err != nil {
+		return nil, &SpecError{Spec: spec, Err: err}
+	}
+	return f, nil
+}
+
+func parseMatcher(spec string) (MatchFunc, error) {
+	spec = strings.TrimSpace(spec)
+	i := strings.IndexByte(spec, '(')
+	if i < 0 {
+		f, ok := LookupMatcher(spec)
+		if !ok {
+			return nil, fmt.Errorf("unknown matcher %q", spec)
+		}
+		return f, nil
+	}
+
+	name := strings.TrimSpace(spec[:i])
+	if !strings.HasSuffix(spec, ")") {
+		return nil, fmt.Errorf("missing ) after %s(", name)
+	}
+	wrap, ok := matchWrappers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown wrapper %q", name)
+	}
+	f, err := parseMatcher(spec[i+1 : len(spec)-1])
+	if err != nil {
+		return nil, err
+	}
+	return wrap(f), nil
+}
+
+// Config returns the Config described by sp.
+func (sp Spec) Config() (Config, error) {
+	c := Config{
+		StrictTies:     sp.StrictTies,
+		MinScore:       sp.MinScore,
+		MaxResults:     sp.MaxResults,
+		ExpandBraces:   sp.ExpandBraces,
+		OrderedResults: sp.OrderedResults,
+	}
+	var err error
+	if sp.Trim != "" {
+		if c.TrimPattern, err = ParseTrimmer(sp.Trim); err != nil {
+			return Config{}, err
+		}
+		c.TrimString = c.TrimPattern
+	}
+	if sp.TrimPattern != "" {
+		if c.TrimPattern, err = ParseTrimmer(sp.TrimPattern); err != nil {
+			return Config{}, err
+		}
+	}
+	if sp.TrimString != "" {
+		if c.TrimString, err = ParseTrimmer(sp.TrimString); err != nil {
+			return Config{}, err
+		}
+	}
+	if sp.Match != "" {
+		if c.Matcher, err = ParseMatcher(sp.Match); err != nil {
+			return Config{}, err
+		}
+	}
+	if sp.TieBreak != "" {
+		f, ok := tieBreaks[sp.TieBreak]
+		if !ok {
+			return Config{}, &SpecError{Spec: sp.TieBreak, Err: fmt.Errorf("unknown tie-break policy %q", sp.TieBreak)}
+		}
+		c.TieBreak = f()
+	}
+	return c, nil
+}
@@ -0,0 +1,39 @@
+package mux
+
+import "time"
+
+// Stats describes the use of an entry, see Config.TrackStats.
+type Stats struct {
+	Matches uint64
+
+	// LastMatched is zero for entries that never matched.
+	LastMatched time.Time
+}
+
+func (m *Mux) hit(e *entry) {
+	if m.stats {
+		e.hits.Add(1)
+		e.lastHit.Store(time.Now().UnixNano())
+	}
+}
+
+func (e *entry) stats() Stats {
+	s := Stats{Matches: e.hits.Load()}
+	if t := e.lastHit.Load(); t != 0 {
+		s.LastMatched = time.Unix(0, t)
+	}
+	return s
+}
+
+// Stats returns the usage statistics of the entry registered under pattern,
+// e.g. to find dead routes. Aliases share the statistics of their entry.
+func (m *Mux) Stats(pattern string) (Stats, bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	_, e, ok := m.lookup(pattern)
+	if !ok {
+		return Stats{}, false
+	}
+	return e.stats(), true
+}
@@ -0,0 +1,144 @@
+package mux
+
+import (
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Templates maps request paths and view names to the html/template
+// templates parsed from the files of a file system matching some globs.
+// The file "users/show.html" serves the path and view "/users/show", and
+// "users/index.html" also serves "/users/" and every path below it without
+// a template of its own. The files are parsed into one template set, so
+// they can include each other by file name.
+type Templates struct {
+	fsys  fs.FS
+	globs []string
+	funcs template.FuncMap
+	m     *Mux
+
+	mtx     sync.Mutex
+	modTime time.Time
+}
+
+// NewTemplates parses the files of fsys matching globs, with the syntax of
+// fs.Glob, with funcs available to them.
+func NewTemplates(fsys fs.FS, funcs template.FuncMap, globs ...string) (*Templates, error) {
+	t := &Templates{fsys: fsys, globs: globs, funcs: funcs, m: NewPathMux()}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload parses the files again and switches to the new templates in one
+// atomic operation. The templates in use are kept if parsing fails.
+func (t *Templates) Reload() error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	// template.ParseFS names templates by base name, so files of the same
+	// name in different directories are parsed one by one instead.
+	set := template.New("").Funcs(t.funcs)
+	next := t.m.Stage()
+	for _, glob := range t.globs {
+		names, err := fs.Glob(t.fsys, glob)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			b, err := fs.ReadFile(t.fsys, name)
+			if err != nil {
+				return err
+			}
+			tmpl, err := set.New(name).Parse(string(b))
+			if err != nil {
+				return err
+			}
+			view := strings.TrimSuffix(name, path.Ext(name))
+			next.Map(view, tmpl)
+			if path.Base(view) == "index" {
+				next.Map(strings.TrimSuffix(view, "index"), tmpl)
+			}
+		}
+	}
+	if err := t.m.Promote(next, nil); err != nil {
+		return err
+	}
+	t.modTime, _ = t.latest()
+	return nil
+}
+
+// latest returns the latest modification time of the files.
+func (t *Templates) latest() (time.Time, error) {
+	var latest time.Time
+	for _, glob := range t.globs {
+		names, err := fs.Glob(t.fsys, glob)
+		if err != nil {
+			return latest, err
+		}
+		for _, name := range names {
+			fi, err := fs.Stat(t.fsys, name)
+			if err != nil {
+				return latest, err
+			}
+			if fi.ModTime().After(latest) {
+				latest = fi.ModTime()
+			}
+		}
+	}
+	return latest, nil
+}
+
+// StartReload checks the files every interval in a new goroutine, calling
+// Reload when one changed, until the returned stop function is called.
+// Errors are passed to onError when it is not nil.
+func (t *Templates) StartReload(interval time.Duration, onError func(err error)) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				latest, err := t.latest()
+				t.mtx.Lock()
+				changed := !latest.Equal(t.modTime)
+				t.mtx.Unlock()
+				if err == nil && changed {
+					err = t.Reload()
+				}
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// Lookup returns the template serving the request path or view name s.
+func (t *Templates) Lookup(s string) (*template.Template, bool) {
+	return MatchAs[*template.Template](t.m, s)
+}
+
+// Execute executes the template serving s with data, failing with a
+// *MatchError wrapping ErrNoMatch when there is none.
+func (t *Templates) Execute(w io.Writer, s string, data interface{}) error {
+	tmpl, ok := t.Lookup(s)
+	if !ok {
+		return &MatchError{Input: s, Err: ErrNoMatch}
+	}
+	return tmpl.Execute(w, data)
+}
@@ -0,0 +1,135 @@
+package mux
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// TenantFunc extracts the tenant of an input, returning the rest of the
+// input to match against the tenant's table, or against the base table
+// when there is no tenant.
+type TenantFunc func(s string) (tenant, rest string, ok bool)
+
+// PathTenant takes the tenant from the first segment of a path:
+// "/acme/users" is "/users" of the tenant "acme".
+var PathTenant = func(s string) (tenant, rest string, ok bool) {
+	s = strings.TrimPrefix(s, "/")
+	tenant, rest, _ = strings.Cut(s, "/")
+	return tenant, "/" + rest, tenant != ""
+}
+
+// HostTenant takes the tenant from the first label of the host of a
+// "host/path" input, such as r.Host + r.URL.Path: "acme.example.com/users"
+// is "/users" of the tenant "acme".
+var HostTenant = func(s string) (tenant, rest string, ok bool) {
+	host, path, _ := strings.Cut(s, "/")
+	tenant, _, ok = strings.Cut(host, ".")
+	return tenant, "/" + path, ok && tenant != ""
+}
+
+// TenantMux routes inputs to a table per tenant, layered with NewChild on
+// a shared base table. Inputs of unknown tenants, or without one, match
+// the base table alone.
+type TenantMux struct {
+	base    *Mux
+	extract TenantFunc
+
+	mtx     sync.RWMutex
+	tenants map[string]*Mux
+}
+
+// NewTenantMux returns a TenantMux extracting tenants with extract, e.g.
+// PathTenant or HostTenant, over base.
+func NewTenantMux(base *Mux, extract TenantFunc) *TenantMux {
+	return &TenantMux{base: base, extract: extract, tenants: make(map[string]*Mux)}
+}
+
+// Base returns the shared base table.
+func (t *TenantMux) Base() *Mux {
+	return t.base
+}
+
+// Tenant returns the table of tenant, adding an empty child of the base
+// table when there is none.
+func (t *TenantMux) Tenant(tenant string) *Mux {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	m, ok := t.tenants[tenant]
+	if !ok {
+		m = NewChild(t.base)
+		t.tenants[tenant] = m
+	}
+	return m
+}
+
+// SetTenant atomically replaces the table of tenant with m, normally made
+// with NewChild of the base table. Matches already running finish on the
+// old table.
+func (t *TenantMux) SetTenant(tenant string, m *Mux) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.tenants[tenant] = m
+}
+
+// RemoveTenant removes the table of tenant.
+func (t *TenantMux) RemoveTenant(tenant string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	delete(t.tenants, tenant)
+}
+
+// Tenants returns the sorted names of the tenants with a table.
+func (t *TenantMux) Tenants() []string {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	return sortedNames(t.tenants)
+}
+
+// route returns the table for s and the part of s to match against it.
+func (t *TenantMux) route(s string) (m *Mux, tenant, rest string) {
+	tenant, rest, ok := t.extract(s)
+	if !ok {
+		return t.base, "", rest
+	}
+	t.mtx.RLock()
+	m, ok = t.tenants[tenant]
+	t.mtx.RUnlock()
+	if !ok {
+		return t.base, tenant, rest
+	}
+	return m, tenant, rest
+}
+
+// Match returns the value of the best match for s in the table of its
+// tenant, along with the tenant.
+func (t *TenantMux) Match(s string, opts ...MatchOption) (val interface{}, tenant string) {
+	r, tenant, _ := t.MatchResultContext(context.Background(), s, opts...)
+	return r.Value, tenant
+}
+
+// MatchResultContext is like Match, returning the whole result and
+// failing like Mux.MatchResultContext.
+func (t *TenantMux) MatchResultContext(ctx context.Context, s string, opts ...MatchOption) (r MatchResult, tenant string, err error) {
+	m, tenant, rest := t.route(s)
+	r, err = m.MatchResultContext(ctx, rest, opts...)
+	return r, tenant, err
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying tenant, e.g. for a handler
+// picked by a TenantMux to read back with TenantFromContext.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant carried by ctx.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
@@ -0,0 +1,138 @@
+package mux
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// Candidate describes one of several entries tied on the best priority and
+// score.
+type Candidate struct {
+	Pattern  string
+	Value    interface{}
+	Index    int
+	Weight   int
+	Priority int
+	Score    int
+}
+
+// TieBreakFunc returns the index into tied of the winning candidate. tied
+// holds at least two candidates, ordered by registration.
+type TieBreakFunc func(s string, tied []Candidate) int
+
+// FirstRegistered picks the tied candidate registered first. Unlike
+// FirstMatchFn it keeps the matcher's own score and only applies on ties.
+var FirstRegistered = func(s string, tied []Candidate) int {
+	return 0
+}
+
+// LastRegistered picks the tied candidate registered last.
+var LastRegistered = func(s string, tied []Candidate) int {
+	return len(tied) - 1
+}
+
+// LongestPattern picks the tied candidate with the longest pattern, the
+// first registered among equally long ones.
+var LongestPattern = func(s string, tied []Candidate) int {
+	best := 0
+	for i, c := range tied {
+		if len(c.Pattern) > len(tied[best].Pattern) {
+			best = i
+		}
+	}
+	return best
+}
+
+// WeightedRandomTieBreak picks a tied candidate at random with probability
+// proportional to its weight. Entries with a weight of zero or less are only
+// picked if no tied entry has a positive weight, in which case the pick is
+// uniform.
+var WeightedRandomTieBreak = func(s string, tied []Candidate) int {
+	total := 0
+	for _, c := range tied {
+		if c.Weight > 0 {
+			total += c.Weight
+		}
+	}
+	if total == 0 {
+		return rand.Intn(len(tied))
+	}
+
+	n := rand.Intn(total)
+	for i, c := range tied {
+		if c.Weight <= 0 {
+			continue
+		}
+		if n -= c.Weight; n < 0 {
+			return i
+		}
+	}
+	return len(tied) - 1
+}
+
+// roundRobinSets bounds the tied sets a RoundRobinTieBreakFn policy keeps
+// a rotation for; the rotation of a set dropped to make room restarts.
+const roundRobinSets = 1024
+
+// RoundRobinTieBreakFn returns a tie-break policy that rotates through tied
+// candidates on successive calls. Every distinct set of tied patterns has
+// its own rotation, so an entry joining a tie gets its turn without
+// starving the others.
+func RoundRobinTieBreakFn() TieBreakFunc {
+	var mtx sync.Mutex
+	next := make(map[string]uint64)
+
+	return func(s string, tied []Candidate) int {
+		// tied is ordered by registration, so the joined patterns name
+		// the set
+		var b strings.Builder
+		for _, c := range tied {
+			b.WriteString(c.Pattern)
+			b.WriteByte(0)
+		}
+		key := b.String()
+
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		n, ok := next[key]
+		if !ok && len(next) >= roundRobinSets {
+			for k := range next {
+				delete(next, k)
+				break
+			}
+		}
+		next[key] = n + 1
+		return int(n % uint64(len(tied)))
+	}
+}
+
+// StickyTieBreak picks a tied candidate by rendezvous hashing of the input,
+// or of the key given with the Affinity option, with each pattern, so the
+// same key always lands on the same pattern and a change to the tied set
+// only moves the keys of the patterns added or removed. Candidates are
+// picked in proportion to their weight like WeightedRandomTieBreak.
+var StickyTieBreak = func(s string, tied []Candidate) int {
+	positive := false
+	for _, c := range tied {
+		positive = positive || c.Weight > 0
+	}
+
+	best, max := 0, math.Inf(-1)
+	for i, c := range tied {
+		w := float64(c.Weight)
+		if !positive {
+			w = 1
+		} else if w <= 0 {
+			continue
+		}
+		// weighted rendezvous: the highest w / -ln(u) wins
+		u := (float64(hash32(s+"\x00"+c.Pattern)) + 0.5) / (1 << 32)
+		if score := w / -math.Log(u); score > max {
+			best, max = i, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,50 @@
+package mux
+
+import (
+	"errors"
+	"net/url"
+	"unicode/utf8"
+)
+
+// TrimErrFunc is a TrimFunc that can reject its input, see
+// Config.TrimStringErr.
+type TrimErrFunc func(s string) (string, error)
+
+var errInvalidUTF8 = errors.New("invalid UTF-8")
+
+// UTF8Check rejects strings that are not valid UTF-8.
+var UTF8Check = func(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", errInvalidUTF8
+	}
+	return s, nil
+}
+
+// PathUnescapeTrim decodes percent-encoded paths such as
+// "/caf%C3%A9", rejecting malformed escapes and encoded invalid UTF-8.
+var PathUnescapeTrim = func(s string) (string, error) {
+	s, err := url.PathUnescape(s)
+	if err != nil {
+		return "", err
+	}
+	return UTF8Check(s)
+}
+
+// TrimErrFn returns f as a TrimErrFunc that never fails.
+func TrimErrFn(f TrimFunc) TrimErrFunc {
+	return func(s string) (string, error) {
+		return f(s), nil
+	}
+}
+
+// CombineTrimErrFn is CombineTrimFn for TrimErrFuncs: f2 runs first, and
+// f1 only on what it accepts.
+func CombineTrimErrFn(f1, f2 TrimErrFunc) TrimErrFunc {
+	return func(s string) (string, error) {
+		s, err := f2(s)
+		if err != nil {
+			return "", err
+		}
+		return f1(s)
+	}
+}
@@ -0,0 +1,32 @@
+package mux
+
+import "net/http"
+
+// MatchAs returns the value of the best match for s as a T, reporting false
+// when nothing matches or the value is not a T.
+func MatchAs[T any](m *Mux, s string, opts ...MatchOption) (T, bool) {
+	v, ok := m.Match(s, opts...).(T)
+	return v, ok
+}
+
+// MatchString returns the value of the best match for s as a string.
+func (m *Mux) MatchString(s string, opts ...MatchOption) (string, bool) {
+	return MatchAs[string](m, s, opts...)
+}
+
+// MatchHandler returns the value of the best match for s as an
+// http.Handler. Values of type func(http.ResponseWriter, *http.Request) are
+// converted to http.HandlerFunc.
+func (m *Mux) MatchHandler(s string, opts ...MatchOption) (http.Handler, bool) {
+	return asHandler(m.Match(s, opts...))
+}
+
+func asHandler(v interface{}) (http.Handler, bool) {
+	switch v := v.(type) {
+	case http.Handler:
+		return v, true
+	case func(http.ResponseWriter, *http.Request):
+		return http.HandlerFunc(v), true
+	}
+	return nil, false
+}
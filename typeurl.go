@@ -0,0 +1,104 @@
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TypeURLTrim reduces a google.protobuf.Any type URL such as
+// "type.googleapis.com/acme.orders.v1.Order" to its full message name,
+// "acme.orders.v1.Order". Names without a host are kept as they are.
+var TypeURLTrim = func(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '/'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimPrefix(s, ".")
+}
+
+// TypeURLMatch matches full message names against patterns that are
+// either message names, possibly with a type URL host, or package
+// wildcards: "acme.orders.*" matches every message of acme.orders and its
+// subpackages, and "*" every message. Exact names score highest, then
+// wildcards of deeper packages.
+var TypeURLMatch = func(pattern, s string, index int) (ok bool, score int) {
+	return namespaceMatch(TypeURLTrim(pattern), s)
+}
+
+// namespaceMatch matches dotted names against exact names and namespace
+// wildcards "a.b.*" matching any name below a.b, "*" matching all.
+func namespaceMatch(pattern, s string) (ok bool, score int) {
+	if pattern == "" || s == "" {
+		return false, 0
+	}
+	if pattern == s {
+		return true, 1 << 16
+	}
+	if pattern == "*" {
+		return true, 0
+	}
+	ns, ok := strings.CutSuffix(pattern, ".*")
+	if !ok || !strings.HasPrefix(s, ns+".") {
+		return false, 0
+	}
+	return true, 1 + strings.Count(ns, ".")
+}
+
+func NewTypeURLMux() *Mux {
+	return New(Config{
+		TrimPattern: TypeURLTrim,
+		TrimString:  TypeURLTrim,
+		Matcher:     TypeURLMatch,
+	})
+}
+
+// TypeHandler handles the serialized value of a google.protobuf.Any.
+type TypeHandler func(typeURL string, value []byte) error
+
+// TypeRouter dispatches google.protobuf.Any messages by type URL, e.g.
+//
+//	r.Dispatch(any.GetTypeUrl(), any.GetValue())
+type TypeRouter struct {
+	m *Mux
+
+	// Unmarshal decodes values for handlers of HandleMessage, e.g.
+	//
+	//	func(b []byte, v any) error { return proto.Unmarshal(b, v.(proto.Message)) }
+	Unmarshal func(b []byte, v interface{}) error
+}
+
+// NewTypeRouter returns an empty TypeRouter.
+func NewTypeRouter() *TypeRouter {
+	return &TypeRouter{m: NewTypeURLMux()}
+}
+
+// Handle routes the messages of pattern, see TypeURLMatch, to h.
+func (t *TypeRouter) Handle(pattern string, h TypeHandler) {
+	t.m.Map(pattern, h)
+}
+
+// Dispatch passes value to the handler of typeURL, returning its error, or
+// a MatchError if there is none.
+func (t *TypeRouter) Dispatch(typeURL string, value []byte) error {
+	res, err := t.m.MatchResult(typeURL)
+	if err != nil {
+		return err
+	}
+	return res.Value.(TypeHandler)(typeURL, value)
+}
+
+// HandleMessage routes the messages of pattern to h, each decoded with
+// t.Unmarshal into a new T, normally a generated message type.
+func HandleMessage[T any](t *TypeRouter, pattern string, h func(*T) error) {
+	t.Handle(pattern, func(typeURL string, value []byte) error {
+		if t.Unmarshal == nil {
+			return errors.New("mux: TypeRouter without Unmarshal")
+		}
+		msg := new(T)
+		if err := t.Unmarshal(value, msg); err != nil {
+			return fmt.Errorf("mux: decoding %s: %w", typeURL, err)
+		}
+		return h(msg)
+	})
+}
@@ -0,0 +1,128 @@
+package mux
+
+import "strings"
+
+// defaultPorts are filled in for URLs of these schemes without a port.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+type urlParts struct {
+	scheme, host, port, path string
+}
+
+// splitURL splits an absolute URL, or URL pattern with "*" parts, into a
+// lowercased scheme and host, a port, defaulted by scheme, and a path
+// without query or fragment. User information is dropped.
+func splitURL(s string) (u urlParts, ok bool) {
+	scheme, rest, ok := strings.Cut(strings.TrimSpace(s), "://")
+	if !ok || scheme == "" {
+		return u, false
+	}
+	authority, path := rest, ""
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if i := strings.LastIndexByte(authority, '@'); i >= 0 {
+		authority = authority[i+1:]
+	}
+	host, port := authority, ""
+	if i := strings.LastIndexByte(authority, ':'); i >= 0 && !strings.Contains(authority[i:], "]") {
+		host, port = authority[:i], authority[i+1:]
+	}
+
+	u.scheme = strings.ToLower(scheme)
+	u.host = strings.TrimSuffix(strings.ToLower(host), ".")
+	u.port = port
+	switch {
+	case u.port != "":
+	case u.scheme == "*":
+		u.port = "*"
+	default:
+		u.port = defaultPorts[u.scheme]
+	}
+	u.path = PathTrim(path)
+	return u, u.host != ""
+}
+
+func (u urlParts) String() string {
+	if u.port == "" {
+		return u.scheme + "://" + u.host + u.path
+	}
+	return u.scheme + "://" + u.host + ":" + u.port + u.path
+}
+
+// URLTrim canonicalizes absolute URLs for URLMatch: scheme and host are
+// lowercased, the default port of the scheme is made explicit, and query
+// and fragment are dropped, so "HTTPS://API.example.com/v1?x=1" becomes
+// "https://api.example.com:443/v1". Other strings are kept as they are.
+var URLTrim = func(s string) string {
+	u, ok := splitURL(s)
+	if !ok {
+		return s
+	}
+	return u.String()
+}
+
+// URLMatch matches absolute URLs against URL patterns constraining
+// scheme, host, port and path separately, e.g.
+// "https://api.example.com:8443/v1/". The scheme and port may be "*", and
+// the host "*" or "*.example.com" for subdomains; a pattern without a port
+// takes the default port of its scheme, any port for the scheme "*".
+// Paths match as with PathMatch, an empty one matching every path. More
+// specific hosts win, then longer paths, then explicit ports and schemes.
+var URLMatch = func(pattern, s string, index int) (ok bool, score int) {
+	pu, ok := splitURL(pattern)
+	if !ok {
+		return false, 0
+	}
+	su, ok := splitURL(s)
+	if !ok {
+		return false, 0
+	}
+
+	var host int
+	switch {
+	case pu.host == "*":
+	case pu.host == su.host:
+		host = 255
+	case strings.HasPrefix(pu.host, "*.") && strings.HasSuffix(su.host, pu.host[1:]):
+		host = 1 + min(strings.Count(pu.host, "."), 253)
+	default:
+		return false, 0
+	}
+
+	if ok, _ = PathMatch(pu.path, su.path, index); !ok {
+		return false, 0
+	}
+	score = host<<24 | min(len(pu.path), 1<<22-1)<<2
+	if pu.port != "*" {
+		if pu.port != su.port {
+			return false, 0
+		}
+		score |= 2
+	}
+	if pu.scheme != "*" {
+		if pu.scheme != su.scheme {
+			return false, 0
+		}
+		score |= 1
+	}
+	return true, score
+}
+
+// NewURLMux returns a Mux of URL patterns, see URLMatch.
+func NewURLMux() *Mux {
+	return New(Config{
+		TrimPattern: URLTrim,
+		TrimString:  URLTrim,
+		Matcher:     URLMatch,
+	})
+}
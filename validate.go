@@ -0,0 +1,296 @@
+package mux
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RouteFileError locates a problem found by ValidateRoutes. Line and
+// Column count from 1.
+type RouteFileError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *RouteFileError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%d:%d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RouteFileError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateRoutes checks the RouteFile read from r more strictly than
+// LoadRoutes and LoadMux do, reporting every unknown or miscased key,
+// value of the wrong type, unknown trimmer, matcher or tie-break policy,
+// pattern mapped twice, bad window schedule and, for regex matchers,
+// pattern that does not compile. The errors are *RouteFileError values
+// joined with errors.Join.
+func ValidateRoutes(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return validateRoutes("", data)
+}
+
+// ValidateFile is ValidateRoutes reading from the named file, e.g. for CI.
+func ValidateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return validateRoutes(path, data)
+}
+
+type routeChecker struct {
+	file string
+	data []byte
+	dec  *json.Decoder
+	errs []*RouteFileError
+
+	// pos holds the offsets of the values seen, by path such as
+	// "routes[2].pattern".
+	pos map[string]int64
+}
+
+func validateRoutes(file string, data []byte) error {
+	c := &routeChecker{file: file, data: data, pos: make(map[string]int64)}
+
+	var f RouteFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		var syntax *json.SyntaxError
+		var typ *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntax):
+			c.errorAt(syntax.Offset, err)
+			return c.err()
+		case errors.As(err, &typ):
+			c.errorAt(typ.Offset, err)
+		default:
+			c.errorAt(0, err)
+			return c.err()
+		}
+	}
+
+	c.dec = json.NewDecoder(bytes.NewReader(data))
+	c.dec.UseNumber()
+	if err := c.value(reflect.TypeOf(f), ""); err != nil {
+		c.errorAt(c.dec.InputOffset(), err)
+		return c.err()
+	}
+	c.checkRoutes(&f)
+	return c.err()
+}
+
+func (c *routeChecker) err() error {
+	sort.SliceStable(c.errs, func(i, j int) bool {
+		a, b := c.errs[i], c.errs[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	errs := make([]error, len(c.errs))
+	for i, err := range c.errs {
+		errs[i] = err
+	}
+	return errors.Join(errs...)
+}
+
+func (c *routeChecker) errorAt(off int64, err error) {
+	if off > int64(len(c.data)) {
+		off = int64(len(c.data))
+	}
+	before := c.data[:off]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(off) - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	c.errs = append(c.errs, &RouteFileError{File: c.file, Line: line, Column: col, Err: err})
+}
+
+// errorIn reports err at the value under path.
+func (c *routeChecker) errorIn(path string, err error) {
+	c.errorAt(c.offsetOf(path), err)
+}
+
+// offsetOf returns the offset of the value under path, or of the nearest
+// enclosing value seen.
+func (c *routeChecker) offsetOf(path string) int64 {
+	for {
+		if off, ok := c.pos[path]; ok {
+			return off
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return 0
+		}
+		path = path[:i]
+	}
+}
+
+// offset returns the offset of the next token.
+func (c *routeChecker) offset() int64 {
+	off := c.dec.InputOffset()
+	for off < int64(len(c.data)) && strings.IndexByte(" \t\r\n,:", c.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+// value walks the next value, which decodes into t, reporting unknown keys
+// of struct objects.
+func (c *routeChecker) value(t reflect.Type, path string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	c.pos[path] = c.offset()
+	tok, err := c.dec.Token()
+	if err != nil {
+		return err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case d == '{' && t.Kind() == reflect.Struct:
+		for c.dec.More() {
+			off := c.offset()
+			tok, err := c.dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			ft, ok := jsonField(t, key)
+			if !ok {
+				c.errorAt(off, fmt.Errorf("unknown key %q", key))
+			}
+			if err := c.value(ft, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case d == '[' && t.Kind() == reflect.Slice:
+		for i := 0; c.dec.More(); i++ {
+			if err := c.value(t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	default:
+		// maps, interface values and mistyped values, which Unmarshal
+		// reported
+		return c.skip()
+	}
+	_, err = c.dec.Token()
+	return err
+}
+
+// skip skips the rest of the array or object just opened.
+func (c *routeChecker) skip() error {
+	for depth := 1; depth > 0; {
+		tok, err := c.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// jsonField returns the type of the field of t named key in JSON, or the
+// empty interface type when there is none.
+func jsonField(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == key {
+			return f.Type, true
+		}
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem(), false
+}
+
+func (c *routeChecker) checkRoutes(f *RouteFile) {
+	var sp Spec
+	if f.Mux != nil {
+		sp = *f.Mux
+	}
+	for _, t := range []struct{ path, spec string }{
+		{".mux.trim", sp.Trim},
+		{".mux.trimPattern", sp.TrimPattern},
+		{".mux.trimString", sp.TrimString},
+	} {
+		if t.spec == "" {
+			continue
+		}
+		if _, err := ParseTrimmer(t.spec); err != nil {
+			c.errorIn(t.path, err)
+			return
+		}
+	}
+	if sp.Match != "" {
+		if _, err := ParseMatcher(sp.Match); err != nil {
+			c.errorIn(".mux.match", err)
+			return
+		}
+	}
+	if _, ok := tieBreaks[sp.TieBreak]; sp.TieBreak != "" && !ok {
+		c.errorIn(".mux.tieBreak", fmt.Errorf("unknown tie-break policy %q", sp.TieBreak))
+	}
+
+	conf, err := sp.Config()
+	if err != nil {
+		return
+	}
+	m := New(conf)
+	// the innermost name of the spec is the matcher itself
+	base := sp.Match[strings.LastIndexByte(sp.Match, '(')+1:]
+	regex := strings.TrimSpace(strings.TrimRight(base, ") ")) == "regex"
+
+	first := make(map[string]int)
+	for i, r := range f.Routes {
+		if r.Window != nil {
+			if _, err := r.Window.compile(); err != nil {
+				c.errorIn(fmt.Sprintf(".routes[%d].window.schedule", i), err)
+			}
+		}
+		path := fmt.Sprintf(".routes[%d].pattern", i)
+		for _, p := range m.expand(r.Pattern) {
+			key := m.trimPattern(p)
+			if j, ok := first[key]; ok {
+				prev := c.line(fmt.Sprintf(".routes[%d].pattern", j))
+				c.errorIn(path, fmt.Errorf("duplicate pattern %q, first mapped at line %d", key, prev))
+				continue
+			}
+			first[key] = i
+			if regex {
+				if _, err := regexp.Compile(key); err != nil {
+					c.errorIn(path, &PatternError{Pattern: key, Err: err})
+				}
+			}
+		}
+	}
+}
+
+// line returns the line of the value under path.
+func (c *routeChecker) line(path string) int {
+	return bytes.Count(c.data[:c.offsetOf(path)], []byte("\n")) + 1
+}
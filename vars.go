@@ -0,0 +1,98 @@
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VarFunc looks up the value of a pattern variable.
+type VarFunc func(name string) (value string, ok bool)
+
+// EnvVars looks pattern variables up in the environment.
+var EnvVars VarFunc = os.LookupEnv
+
+// MapVars looks pattern variables up in vars.
+func MapVars(vars map[string]string) VarFunc {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+// ExpandVars replaces every "${NAME}" in pattern with the value vars gives
+// for NAME. "$${" stands for a literal "${", and a "$" not followed by "{"
+// is kept as is, so regexp anchors are unaffected. An undefined variable or
+// unterminated placeholder is reported as a *PatternError, along with the
+// partly expanded result in which it is kept verbatim.
+func ExpandVars(pattern string, vars VarFunc) (string, error) {
+	if !strings.Contains(pattern, "${") {
+		return pattern, nil
+	}
+
+	var b strings.Builder
+	var err error
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "$${"):
+			b.WriteString("${")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "${"):
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				if err == nil {
+					err = &PatternError{Pattern: pattern, Err: errors.New("unterminated variable")}
+				}
+				b.WriteString(pattern[i:])
+				return b.String(), err
+			}
+			name := pattern[i+2 : i+end]
+			if v, ok := vars(name); ok {
+				b.WriteString(v)
+			} else {
+				if err == nil {
+					err = &PatternError{Pattern: pattern, Err: fmt.Errorf("undefined variable %q", name)}
+				}
+				b.WriteString(pattern[i : i+end+1])
+			}
+			i += end
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String(), err
+}
+
+// TryMap is like Map but fails, leaving the Mux unchanged, when a variable
+// in pattern cannot be expanded or a table capped with EvictReject has no
+// room for it.
+func (m *Mux) TryMap(pattern string, val interface{}) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.vars != nil {
+		if _, err := ExpandVars(pattern, m.vars); err != nil {
+			return err
+		}
+	}
+	patterns := m.expand(pattern)
+	if m.maxEntries > 0 && m.eviction == EvictReject {
+		n := m.size()
+		added := make(map[string]bool, len(patterns))
+		for _, p := range patterns {
+			// alternatives trimming to the same key make one entry
+			if key, _, ok := m.lookup(p); !ok && !added[key] {
+				added[key] = true
+				n++
+			}
+		}
+		if n > m.maxEntries {
+			return ErrFull
+		}
+	}
+	for _, p := range patterns {
+		m.mapEntry(p, val)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package mux
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultHistory is the number of versions kept when Config.History is
+// zero.
+const DefaultHistory = 10
+
+// Version describes a checkpoint of the table.
+type Version struct {
+	Version int
+	Time    time.Time
+	Entries int
+}
+
+type checkpoint struct {
+	v       Version
+	entries []Entry
+}
+
+// Checkpoint records the current entries as a new version, returning its
+// number, so a bad change can be undone with Rollback. Only the entries are
+// recorded, not the matcher, trimmers or other settings. Values are shared
+// with the checkpoint, not copied.
+func (m *Mux) Checkpoint() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.version++
+	entries := m.entries()
+	m.versions = append(m.versions, checkpoint{
+		v:       Version{Version: m.version, Time: time.Now(), Entries: len(entries)},
+		entries: entries,
+	})
+
+	n := m.history
+	if n <= 0 {
+		n = DefaultHistory
+	}
+	if len(m.versions) > n {
+		m.versions = append(m.versions[:0:0], m.versions[len(m.versions)-n:]...)
+	}
+	return m.version
+}
+
+// Versions returns the versions kept, oldest first.
+func (m *Mux) Versions() []Version {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	versions := make([]Version, len(m.versions))
+	for i, c := range m.versions {
+		versions[i] = c.v
+	}
+	return versions
+}
+
+// Rollback restores the entries recorded by Checkpoint as version in one
+// locked operation. Versions are kept, so a rollback can itself be undone by
+// rolling forward to a later one. Entries present in both keep their
+// statistics, and are not reported to OnEvict unless their value changes.
+// Restored lazy entries run their provider again.
+func (m *Mux) Rollback(version int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, c := range m.versions {
+		if c.v.Version == version {
+			m.replaceEntries(c.entries)
+			return nil
+		}
+	}
+	return fmt.Errorf("mux: no version %d", version)
+}
+
+// replaceEntries makes entries the whole table, deleting the other ones.
+func (m *Mux) replaceEntries(entries []Entry) {
+	keep := make(map[string]bool, len(entries))
+	for _, x := range entries {
+		keep[x.Pattern] = true
+	}
+	for key, e := range m.m {
+		if !keep[key] {
+			m.remove(key, e)
+		}
+	}
+	m.importEntries(entries)
+	m.order = nil
+}
@@ -0,0 +1,91 @@
+package mux
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window bounds when an entry matches. Zero times leave that side open.
+type Window struct {
+	NotBefore time.Time `json:"notBefore,omitempty"`
+	NotAfter  time.Time `json:"notAfter,omitempty"`
+
+	// Schedule, when set, further limits the entry to the times covered by
+	// a schedule as accepted by ScheduleMatch, e.g. "Mon-Fri 09:00-17:00",
+	// in the local time zone.
+	Schedule string `json:"schedule,omitempty"`
+}
+
+type window struct {
+	Window
+	sched *schedule
+}
+
+// newWindow compiles w. Windows whose schedule cannot be parsed are never
+// active.
+func newWindow(w *Window) *window {
+	c := &window{Window: *w}
+	if w.Schedule != "" {
+		c.sched, _ = parseSchedule(w.Schedule)
+	}
+	return c
+}
+
+func (w *window) export() *Window {
+	if w == nil {
+		return nil
+	}
+	x := w.Window
+	return &x
+}
+
+// sameWindow compares windows with time.Time.Equal, which unlike == and
+// reflect.DeepEqual ignores locations and monotonic clock readings.
+func sameWindow(a, b *Window) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.NotBefore.Equal(b.NotBefore) && a.NotAfter.Equal(b.NotAfter) && a.Schedule == b.Schedule
+}
+
+func (w *Window) compile() (*window, error) {
+	c := newWindow(w)
+	if w.Schedule != "" && c.sched == nil {
+		return nil, fmt.Errorf("mux: bad schedule %q", w.Schedule)
+	}
+	return c, nil
+}
+
+func (w *window) active(t time.Time) bool {
+	if !w.NotBefore.IsZero() && t.Before(w.NotBefore) {
+		return false
+	}
+	if !w.NotAfter.IsZero() && t.After(w.NotAfter) {
+		return false
+	}
+	return w.Schedule == "" || (w.sched != nil && w.sched.contains(t.Local()))
+}
+
+// MapWithWindow maps pattern to val, skipping it when matching outside w,
+// e.g. to register holiday rules ahead of time. It fails, mapping nothing,
+// if the schedule of w cannot be parsed, and with ErrFull for patterns a
+// table capped with EvictReject has no room for.
+func (m *Mux) MapWithWindow(pattern string, val interface{}, w Window) error {
+	c, err := w.compile()
+	if err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	err = nil
+	for _, p := range m.expand(pattern) {
+		if e := m.mapEntry(p, val); e != nil {
+			e.window = c
+		} else {
+			err = ErrFull
+		}
+	}
+	return err
+}